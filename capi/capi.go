@@ -0,0 +1,130 @@
+/*
+Command capi is a C shared library exposing the encodings of package co with
+a stable C ABI. Build it, along with its header liburbitgob.h, with:
+
+	go build -buildmode=c-shared -o liburbitgob.so ./capi
+
+Every function that returns a string returns a new NUL-terminated string
+allocated with malloc, which the caller owns and must release with
+urbit_free. Functions that can fail return NULL on failure and, if err is not
+NULL, set *err to a message that must also be released with urbit_free; on
+success *err is set to NULL. Arguments are only read, never kept or freed.
+*/
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// Strings returned by liburbitgob are allocated with malloc and owned by the
+// caller, who must release them with urbit_free. Functions that can fail
+// return NULL and, if err is not NULL, set *err to an error message that must
+// be released with urbit_free as well.
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+func main() {}
+
+// result hands a string or an error over to C.
+func result(s string, err error, errOut **C.char) *C.char {
+
+	if err != nil {
+		if errOut != nil {
+			*errOut = C.CString(err.Error())
+		}
+		return nil
+	}
+
+	if errOut != nil {
+		*errOut = nil
+	}
+
+	return C.CString(s)
+}
+
+func boolInt(b bool) C.int {
+
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+//export urbit_free
+func urbit_free(s *C.char) {
+
+	C.free(unsafe.Pointer(s))
+}
+
+//export urbit_patp
+func urbit_patp(dec *C.char, err **C.char) *C.char {
+
+	p, e := co.Patp(C.GoString(dec))
+	return result(p, e, err)
+}
+
+//export urbit_patp_u64
+func urbit_patp_u64(v C.uint64_t) *C.char {
+
+	return C.CString(string(co.AppendPatp(nil, uint64(v))))
+}
+
+//export urbit_patp2dec
+func urbit_patp2dec(name *C.char, err **C.char) *C.char {
+
+	dec, e := co.Patp2Dec(C.GoString(name))
+	return result(dec, e, err)
+}
+
+//export urbit_patq
+func urbit_patq(dec *C.char, err **C.char) *C.char {
+
+	q, e := co.Patq(C.GoString(dec))
+	return result(q, e, err)
+}
+
+//export urbit_patq_u64
+func urbit_patq_u64(v C.uint64_t) *C.char {
+
+	return C.CString(string(co.AppendPatq(nil, uint64(v))))
+}
+
+//export urbit_patq2dec
+func urbit_patq2dec(name *C.char, err **C.char) *C.char {
+
+	dec, e := co.Patq2Dec(C.GoString(name))
+	return result(dec, e, err)
+}
+
+//export urbit_clan
+func urbit_clan(name *C.char, err **C.char) *C.char {
+
+	clan, e := co.Clan(C.GoString(name))
+	return result(clan, e, err)
+}
+
+//export urbit_sein
+func urbit_sein(name *C.char, err **C.char) *C.char {
+
+	sein, e := co.Sein(C.GoString(name))
+	return result(sein, e, err)
+}
+
+//export urbit_is_valid_patp
+func urbit_is_valid_patp(name *C.char) C.int {
+
+	return boolInt(co.IsValidPatp(C.GoString(name)))
+}
+
+//export urbit_is_valid_patq
+func urbit_is_valid_patq(name *C.char) C.int {
+
+	return boolInt(co.IsValidPatq(C.GoString(name)))
+}
@@ -0,0 +1,66 @@
+//go:build cgo
+// +build cgo
+
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCHarness(t *testing.T) {
+
+	if runtime.GOOS != "linux" {
+		t.Skip("the C harness is only built on Linux")
+	}
+	if testing.Short() {
+		t.Skip("building the shared library is slow")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler found")
+	}
+
+	dir := t.TempDir()
+
+	build := exec.Command(goTool, "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "liburbitgob.so"), ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("building the shared library: %v\n%s", err, out)
+	}
+
+	harness := filepath.Join(dir, "harness")
+	compile := exec.Command(cc, "-o", harness, filepath.Join("testdata", "harness.c"),
+		"-I", dir, "-L", dir, "-lurbitgob", "-Wl,-rpath,"+dir)
+	if out, err := compile.CombinedOutput(); err != nil {
+		t.Fatalf("compiling the harness: %v\n%s", err, out)
+	}
+
+	out, err := exec.Command(harness).CombinedOutput()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, `patp: ~sampel-palnet
+patp: error: invalid integer string: sampel
+patp2dec: 1624961343
+patq: ~ronler-talpur
+patq2dec: 1624961343
+clan: planet
+sein: ~talpur
+sein: error: invalid @p: ~sampel-palnez
+patp_u64: ~fipfes-fipfes-dostec-risfen
+patq_u64: ~doznec-dozzod
+patp: NULL
+is_valid_patp: 1 0
+is_valid_patq: 1 0
+`, string(out))
+}
@@ -0,0 +1,46 @@
+#include <stdio.h>
+
+#include "liburbitgob.h"
+
+static void print(const char *label, char *s, char *err) {
+	if (s != NULL) {
+		printf("%s: %s\n", label, s);
+	} else {
+		printf("%s: error: %s\n", label, err);
+	}
+	urbit_free(s);
+	urbit_free(err);
+}
+
+int main(void) {
+	char *s, *err;
+
+	s = urbit_patp("1624961343", &err);
+	print("patp", s, err);
+	s = urbit_patp("sampel", &err);
+	print("patp", s, err);
+	s = urbit_patp2dec("~sampel-palnet", &err);
+	print("patp2dec", s, err);
+	s = urbit_patq("1624961343", &err);
+	print("patq", s, err);
+	s = urbit_patq2dec("~ronler-talpur", &err);
+	print("patq2dec", s, err);
+	s = urbit_clan("~sampel-palnet", &err);
+	print("clan", s, err);
+	s = urbit_sein("~sampel-palnet", &err);
+	print("sein", s, err);
+	s = urbit_sein("~sampel-palnez", &err);
+	print("sein", s, err);
+	print("patp_u64", urbit_patp_u64(18446744073709551615ULL), NULL);
+	print("patq_u64", urbit_patq_u64(65536), NULL);
+
+	/* err may be NULL when the message isn't wanted. */
+	char *p = urbit_patp("-", NULL);
+	printf("patp: %s\n", p == NULL ? "NULL" : p);
+	urbit_free(p);
+
+	printf("is_valid_patp: %d %d\n", urbit_is_valid_patp("~zod"), urbit_is_valid_patp("~doznec"));
+	printf("is_valid_patq: %d %d\n", urbit_is_valid_patq("~doznec"), urbit_is_valid_patq("~nec-"));
+
+	return 0;
+}
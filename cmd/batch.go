@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const stdinHelp string = `With - as the argument, or with no argument when stdin is piped, every line of
+stdin is converted in turn, and blank lines are skipped. Results are written in
+the order of the lines as soon as they are ready. A line that fails is reported
+on stderr, or in its JSON record, and the rest are still converted.`
+
+// readsStdin reports whether a command that converts one value at a time was
+// asked to convert the lines of stdin instead.
+func readsStdin(args []string, e env) bool {
+
+	return (len(args) == 1 && args[0] == "-") || (len(args) == 0 && e.piped)
+}
+
+// isPiped reports whether f is a pipe or a file rather than a terminal.
+func isPiped(f *os.File) bool {
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// batch converts every line of stdin with each, writes the results in order,
+// and returns the exit code: invalid input if any line failed.
+func (c *command) batch(ctx context.Context, e env) int {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		// lines holds the line number of every value sent to Stream, which
+		// only knows the index of each value.
+		lines   []int
+		readErr error
+	)
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+
+		scanner := bufio.NewScanner(e.stdin)
+		for n := 1; scanner.Scan(); n++ {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			mu.Lock()
+			lines = append(lines, n)
+			mu.Unlock()
+			select {
+			case in <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		mu.Lock()
+		readErr = scanner.Err()
+		mu.Unlock()
+	}()
+
+	code := codeOK
+	for r := range co.Stream(ctx, c.each, in, co.BatchOptions{Workers: *c.workers}) {
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		if r.Err != nil {
+			code = codeInvalidInput
+			if e.output == outputText {
+				mu.Lock()
+				line := lines[r.Index]
+				mu.Unlock()
+				fmt.Fprintf(e.stderr, "%s %s: line %d: %v\n", programName, c.name, line, r.Err)
+				continue
+			}
+		}
+
+		writeResult(e, c, []string{r.In}, c.value(r.Out), r.Err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	switch {
+	case ctx.Err() != nil:
+		fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, c.name, ctx.Err())
+		return codeInterrupted
+	case readErr != nil:
+		fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, c.name, readErr)
+		return codeFailure
+	}
+
+	return code
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	errArgCount     string = "expects %s, got %d"
+	errArgCountFrom string = "%d to %d arguments"
+)
+
+// command is a subcommand of the CLI. Every run of the CLI builds its commands
+// afresh, so their flags start from their defaults.
+type command struct {
+	name string
+	// args describes the arguments in usage, such as "<@p>".
+	args    string
+	summary string
+	// detail, if not empty, is shown by help after the summary.
+	detail  string
+	minArgs int
+	maxArgs int
+	// input is the kind of the argument, for commands that take one.
+	input inputKind
+	flags *flag.FlagSet
+	run   func(ctx context.Context, e env, args []string) (interface{}, error)
+
+	// each, if not nil, converts one value, which lets the command convert
+	// every line of stdin as well as its argument, with workers at once.
+	each    co.ConvertFunc
+	workers *int
+	// bools is set if each returns "true" or "false", which are written to
+	// JSON as booleans.
+	bools bool
+	// raw is set if the command writes its own output, so that only its
+	// error is written for it. Such a command can't write JSON, so it is
+	// refused with --output json.
+	raw bool
+}
+
+func newCommand(name, args, summary string, minArgs, maxArgs int) *command {
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+
+	return &command{
+		name:    name,
+		args:    args,
+		summary: summary,
+		minArgs: minArgs,
+		maxArgs: maxArgs,
+		flags:   flags,
+	}
+}
+
+func lookup(cmds []*command, name string) *command {
+
+	for _, cmd := range cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// execute parses the flags and arguments of the command, runs it and prints
+// its result, and returns the exit code.
+func (c *command) execute(ctx context.Context, args []string, e env) int {
+
+	if err := c.flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			c.usage(e.stdout)
+			return codeOK
+		}
+		fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, c.name, err)
+		c.usage(e.stderr)
+		return codeUsage
+	}
+
+	if c.raw && e.output != outputText {
+		fmt.Fprintf(e.stderr, "%s %s: "+errOutputRaw+"\n", programName, c.name, e.output, c.name)
+		return codeUsage
+	}
+
+	args = c.flags.Args()
+	if c.each != nil && readsStdin(args, e) {
+		return c.batch(ctx, e)
+	}
+
+	if len(args) < c.minArgs || len(args) > c.maxArgs {
+		fmt.Fprintf(e.stderr, "%s %s: "+errArgCount+"\n", programName, c.name, c.argCount(), len(args))
+		c.usage(e.stderr)
+		return codeUsage
+	}
+
+	result, err := c.run(ctx, e, args)
+	if !c.raw || err != nil {
+		writeResult(e, c, args, result, err)
+	}
+	if err != nil {
+		return exitCode(err)
+	}
+
+	return codeOK
+}
+
+// value returns the result of each as it is written to JSON.
+func (c *command) value(result string) interface{} {
+
+	if c.bools {
+		return result == "true"
+	}
+
+	return result
+}
+
+func (c *command) argCount() string {
+
+	switch {
+	case c.minArgs != c.maxArgs:
+		return fmt.Sprintf(errArgCountFrom, c.minArgs, c.maxArgs)
+	case c.minArgs == 1:
+		return "1 argument"
+	}
+
+	return fmt.Sprintf("%d arguments", c.minArgs)
+}
+
+func (c *command) usage(w io.Writer) {
+
+	options := ""
+	hasFlags := false
+	c.flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		options = " [options]"
+	}
+
+	fmt.Fprintf(w, "Usage: %s %s%s %s\n\n", programName, c.name, options, c.args)
+	fmt.Fprintf(w, "%s%s.\n", strings.ToUpper(c.summary[:1]), c.summary[1:])
+	if c.detail != "" {
+		fmt.Fprintf(w, "\n%s\n", c.detail)
+	}
+	if c.each != nil {
+		fmt.Fprintf(w, "\n%s\n", stdinHelp)
+	}
+
+	if hasFlags {
+		fmt.Fprintf(w, "\nOptions:\n")
+		c.flags.SetOutput(w)
+		c.flags.PrintDefaults()
+		c.flags.SetOutput(ioutil.Discard)
+	}
+}
+
+// inputError is an error caused by the input a command was given, rather than
+// by the command failing.
+type inputError struct {
+	err error
+}
+
+func (e inputError) Error() string {
+
+	return e.err.Error()
+}
+
+func (e inputError) Unwrap() error {
+
+	return e.err
+}
+
+// invalidInput marks an error, if there is one, as caused by the input.
+func invalidInput(err error) error {
+
+	if err == nil {
+		return nil
+	}
+
+	return inputError{err}
+}
+
+// exitCode returns the exit code for the kind of error a command returned.
+func exitCode(err error) int {
+
+	var input inputError
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codeInterrupted
+	case errors.As(err, &input):
+		return codeInvalidInput
+	}
+
+	return codeFailure
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/conformance"
+	ugi "github.com/deelawn/urbit-gob/internal"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+const (
+	// Commands
+	cmdPatp     string = "patp"
+	cmdPatp2Dec string = "patp2dec"
+	cmdPatp2Hex string = "patp2hex"
+
+	cmdPatq     string = "patq"
+	cmdPatq2Dec string = "patq2dec"
+	cmdPatq2Hex string = "patq2hex"
+
+	cmdHex2Patp string = "hex2patp"
+	cmdHex2Patq string = "hex2patq"
+
+	cmdClan   string = "clan"
+	cmdSein   string = "sein"
+	cmdEqPatq string = "eqpatq"
+
+	cmdIsValidPat  string = "isvalidpat"
+	cmdIsValidPatp string = "isvalidpatp"
+	cmdIsValidPatq string = "isvalidpatq"
+
+	cmdExplain string = "explain"
+
+	cmdVectors      string = "vectors"
+	cmdCheckVectors string = "checkvectors"
+
+	cmdVerify string = "verify"
+
+	cmdHelp string = "help"
+
+	// Others
+	errCheckpoint   string = "checkpoint %s is for [%d, %d], not [%d, %d]"
+	errVerifyFailed string = "%d values failed verification, starting with %v"
+
+	defaultVectorSamples int = 100
+)
+
+// commands returns every command of the CLI, in the order usage lists them.
+func commands() []*command {
+
+	return []*command{
+		perValue(cmdPatp, inputNumber, "converts a number to a @p-encoded string", co.Patp),
+		perValue(cmdPatp2Dec, inputPatp, "converts a @p-encoded string to a decimal-encoded string", co.Patp2Dec),
+		perValue(cmdPatp2Hex, inputPatp, "converts a @p-encoded string to a hex-encoded string", co.Patp2Hex),
+		perValue(cmdPatq, inputNumber, "converts a number to a @q-encoded string", co.Patq),
+		perValue(cmdPatq2Dec, inputPatq, "converts a @q-encoded string to a decimal-encoded string", co.Patq2Dec),
+		perValue(cmdPatq2Hex, inputPatq, "converts a @q-encoded string to a hex-encoded string", co.Patq2Hex),
+		perValue(cmdHex2Patp, inputHex, "converts a hex-encoded string to a @p-encoded string", co.Hex2Patp),
+		perValue(cmdHex2Patq, inputHex, "converts a hex-encoded string to a @q-encoded string", co.Hex2Patq),
+		perValue(cmdClan, inputPatp, "determines the ship class of a @p value", co.Clan),
+		perValue(cmdSein, inputPatp, "determines the parent of a @p value", co.Sein),
+		newEqPatq(),
+		predicate(cmdIsValidPat, inputOther, "weakly checks if a string is a valid @p or @q value", co.IsValidPat),
+		predicate(cmdIsValidPatp, inputPatp, "validates a @p string", co.IsValidPatp),
+		predicate(cmdIsValidPatq, inputPatq, "validates a @q string", co.IsValidPatq),
+		newExplain(),
+		newVectors(),
+		newCheckVectors(),
+		newVerify(),
+		newCSV(),
+		newRepl(),
+	}
+}
+
+// perValue returns a command that converts one value at a time with each: its
+// one argument, or every line of stdin.
+func perValue(name string, kind inputKind, summary string, each co.ConvertFunc) *command {
+
+	cmd := newCommand(name, kind.usage()+" | -", summary, 1, 1)
+	cmd.input = kind
+	cmd.each = each
+	cmd.workers = cmd.flags.Int("workers", 1, "the number of lines of stdin to convert at once, or 0 for the number of CPUs")
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+		result, err := each(args[0])
+		return cmd.value(result), invalidInput(err)
+	}
+
+	return cmd
+}
+
+// predicate returns a command that prints whether its one argument satisfies
+// check.
+func predicate(name string, kind inputKind, summary string, check func(string) bool) *command {
+
+	cmd := perValue(name, kind, summary, func(arg string) (string, error) {
+		return strconv.FormatBool(check(arg)), nil
+	})
+	cmd.bools = true
+
+	return cmd
+}
+
+func newEqPatq() *command {
+
+	cmd := newCommand(cmdEqPatq, "<@q> <@q>", "performs an equality comparison on @q values", 2, 2)
+	cmd.detail = "Leading zero bytes are ignored, so ~dozzod-sampel and ~sampel are equal."
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+		result, err := co.EqPatq(args[0], args[1])
+		return result, invalidInput(err)
+	}
+
+	return cmd
+}
+
+func newExplain() *command {
+
+	cmd := perValue(cmdExplain, inputOther, "shows every step of converting a number to @p, or a @p to a number", explain)
+	cmd.args = "<number or @p> | -"
+	cmd.detail = "A @p is recognized by its leading ~; anything else is read as a number."
+
+	return cmd
+}
+
+func explain(arg string) (string, error) {
+
+	var (
+		trace *co.PatpTrace
+		err   error
+	)
+
+	if strings.HasPrefix(arg, "~") {
+		trace, err = co.TracePatp2Dec(arg)
+	} else {
+		trace, err = co.TracePatp(arg)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(trace.Explain(), "\n"), nil
+}
+
+func newVectors() *command {
+
+	cmd := newCommand(cmdVectors, "<seed>", "writes conformance vectors for a random seed", 1, 1)
+	cmd.detail = "The same seed and sample count always give the same file."
+	samples := cmd.flags.Int("samples", defaultVectorSamples, "the number of random vectors for each ship class")
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+
+		seed, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+
+		file, err := conformance.Generate(seed, *samples)
+		if err != nil {
+			return nil, err
+		}
+
+		return vectorFile{file}, nil
+	}
+
+	return cmd
+}
+
+// vectorFile is the result of vectors: the file in its canonical form as text,
+// or as a JSON object.
+type vectorFile struct {
+	*conformance.File
+}
+
+func (f vectorFile) String() string {
+
+	var b strings.Builder
+	// Writing to a strings.Builder can't fail.
+	_ = conformance.Write(&b, f.File)
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func newCheckVectors() *command {
+
+	cmd := newCommand(cmdCheckVectors, "<file>", "checks this implementation against a conformance vector file", 1, 1)
+	cmd.detail = "Every mismatch is reported, and any mismatch is a failure."
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+		defer f.Close()
+
+		file, err := conformance.Read(f)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+
+		mismatches := conformance.Check(file)
+		if len(mismatches) == 0 {
+			return fmt.Sprintf("all %d vectors match", len(file.Vectors)), nil
+		}
+
+		lines := make([]string, len(mismatches))
+		for i, m := range mismatches {
+			lines[i] = m.String()
+		}
+
+		return nil, errors.New(strings.Join(lines, "\n"))
+	}
+
+	return cmd
+}
+
+func newVerify() *command {
+
+	cmd := newCommand(cmdVerify, "<start> <end>", "checks that fein and fynd are inverses over a range", 2, 2)
+	cmd.detail = `The range is [start, end], including end, and both may be decimal or
+0x-prefixed hex. A sample of every chunk is also checked against the big
+integer fein and fynd. With -checkpoint, progress is saved to the file as it
+goes, including when interrupted, and an existing checkpoint for the same range
+is resumed.`
+	checkpoint := cmd.flags.String("checkpoint", "", "the `file` to save progress to and resume from")
+	workers := cmd.flags.Int("workers", 0, "the number of values to check at once (default the number of CPUs)")
+	chunk := cmd.flags.Uint64("chunk", ob.DefaultVerifyChunk, "the number of values a worker checks at a time")
+	every := cmd.flags.Duration("progress", time.Second, "how often to report progress and save the checkpoint")
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+
+		start, err := strconv.ParseUint(args[0], 0, 64)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+		end, err := strconv.ParseUint(args[1], 0, 64)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+		if start > end {
+			return nil, invalidInput(fmt.Errorf(ugi.ErrVerifyRange, start, end))
+		}
+
+		state := ob.NewVerifyState(start, end)
+		if *checkpoint != "" {
+			if state, err = loadCheckpoint(*checkpoint, state); err != nil {
+				return nil, invalidInput(err)
+			}
+		}
+
+		began := time.Now()
+		last := began
+		state, err = ob.Verify(ctx, state, ob.VerifyOptions{
+			Workers: *workers,
+			Chunk:   *chunk,
+			Progress: func(s ob.VerifyState) {
+				if time.Since(last) < *every {
+					return
+				}
+				last = time.Now()
+				fmt.Fprintf(e.stderr, "%.2f%% checked, next %#x, %d failures, %s\n",
+					100*float64(s.Next-s.Start)/(float64(s.End-s.Start)+1), s.Next, s.Failures,
+					time.Since(began).Round(time.Second))
+				if *checkpoint != "" {
+					if err := saveCheckpoint(*checkpoint, s); err != nil {
+						fmt.Fprintln(e.stderr, err)
+					}
+				}
+			},
+		})
+		if *checkpoint != "" {
+			if err := saveCheckpoint(*checkpoint, state); err != nil {
+				return nil, err
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if state.Failures > 0 {
+			return nil, fmt.Errorf(errVerifyFailed, state.Failures, state.Examples)
+		}
+
+		return verification(state), nil
+	}
+
+	return cmd
+}
+
+// verification is the result of verify: a summary as text, or the final state
+// as a JSON object.
+type verification ob.VerifyState
+
+func (v verification) String() string {
+
+	// The count can be 2^64, one more than a uint64 holds.
+	count := big.NewInt(0).SetUint64(v.End - v.Start)
+	count.Add(count, big.NewInt(1))
+
+	return fmt.Sprintf("all %s values in [%#x, %#x] verified", count, v.Start, v.End)
+}
+
+// loadCheckpoint returns the state saved at path, or state itself if there is
+// no file there yet.
+func loadCheckpoint(path string, state ob.VerifyState) (ob.VerifyState, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	var saved ob.VerifyState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return state, err
+	}
+	if saved.Start != state.Start || saved.End != state.End {
+		return state, fmt.Errorf(errCheckpoint, path, saved.Start, saved.End, state.Start, state.End)
+	}
+
+	return saved, nil
+}
+
+// saveCheckpoint writes the state to a temporary file and renames it over
+// path, so that an interrupted write never loses the previous checkpoint.
+func saveCheckpoint(path string, state ob.VerifyState) error {
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(path+".tmp", append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	cmdCSV string = "csv"
+
+	errCSVConversion string = "invalid conversion %q: must be column:command or column:command:name"
+	errCSVCommand    string = "%s cannot convert a column; use a command that converts one value, such as patp"
+	errCSVColumn     string = "no column named %s"
+	errCSVNoConvert  string = "nothing to convert; use -convert column:command"
+	errCSVNoInput    string = "no file given, and stdin is not piped"
+	errCSVBadRows    string = "%d of %d rows could not be converted"
+	errCSVNoHeader   string = "no header row naming the columns"
+	errCSVShortRow   string = "no column %s in this row"
+)
+
+// csvConversion is a column to convert with a command, and the name of the
+// column to append the results as.
+type csvConversion struct {
+	column  string
+	command *command
+	name    string
+	index   int
+}
+
+// csvConversions is a flag.Value that collects -convert options.
+type csvConversions []csvConversion
+
+func (c *csvConversions) String() string {
+
+	specs := make([]string, len(*c))
+	for i, conv := range *c {
+		specs[i] = conv.column + ":" + conv.command.name + ":" + conv.name
+	}
+
+	return strings.Join(specs, ",")
+}
+
+func (c *csvConversions) Set(value string) error {
+
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf(errCSVConversion, value)
+	}
+
+	cmd := lookup(commands(), parts[1])
+	if cmd == nil {
+		return fmt.Errorf(errUnknownCommand, parts[1])
+	}
+	if cmd.each == nil {
+		return fmt.Errorf(errCSVCommand, cmd.name)
+	}
+
+	conv := csvConversion{column: parts[0], command: cmd, name: parts[0] + "_" + cmd.name}
+	if len(parts) == 3 && parts[2] != "" {
+		conv.name = parts[2]
+	}
+
+	*c = append(*c, conv)
+	return nil
+}
+
+func newCSV() *command {
+
+	cmd := newCommand(cmdCSV, "<file> | -", "converts columns of a CSV or TSV file, appending the results as new columns", 0, 1)
+	cmd.detail = `The first row must name the columns. Each -convert column:command converts
+every value of the column with a command that converts one value, such as patp,
+patp2dec, clan or sein, and appends the results as a column named
+column_command, or as the column named by column:command:name.
+
+The file is read from stdin if it is - or missing and stdin is piped, and a file
+ending in .tsv is read as TSV, which has no quoting: every line is a row and
+every tab separates cells, so a quote in a TSV cell is just a quote. Rows are
+copied as they were written, quoting included, with the new cells quoted as they
+need to be. A row that can't be parsed, is missing the column or can't be
+converted gets empty cells and is reported on stderr with its line, and the
+rest of the file is still converted.`
+	cmd.raw = true
+	var conversions csvConversions
+	cmd.flags.Var(&conversions, "convert", "a `column:command` to convert, which may be repeated")
+	tsv := cmd.flags.Bool("tsv", false, "read and write tab-separated values")
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+
+		if len(conversions) == 0 {
+			return nil, invalidInput(errors.New(errCSVNoConvert))
+		}
+
+		in := e.stdin
+		comma := ','
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return nil, invalidInput(err)
+			}
+			defer f.Close()
+			in = f
+			if strings.HasSuffix(strings.ToLower(args[0]), ".tsv") {
+				comma = '\t'
+			}
+		} else if len(args) == 0 && !e.piped {
+			return nil, invalidInput(errors.New(errCSVNoInput))
+		}
+		if *tsv {
+			comma = '\t'
+		}
+
+		return nil, convertCSV(ctx, e, in, comma, conversions)
+	}
+
+	return cmd
+}
+
+/*
+convertCSV copies CSV from in to stdout, appending the conversions to every
+row. Rows are copied as they were written, quoting included, and only the new
+cells are quoted as they need to be. A row that can't be parsed, is missing a
+column or fails to convert gets empty cells for what it lacks, and is reported
+on stderr with the line it starts on and counted in the error.
+*/
+func convertCSV(ctx context.Context, e env, in io.Reader, comma rune, conversions csvConversions) error {
+
+	records := &csvRecords{in: bufio.NewReader(in), comma: comma}
+
+	text, _, err := records.next()
+	if err == io.EOF {
+		return invalidInput(errors.New(errCSVNoHeader))
+	}
+	if err != nil {
+		return err
+	}
+	header, err := parseCSVRecord(text, 1, comma)
+	if err != nil {
+		return invalidInput(err)
+	}
+
+	names := make([]string, len(conversions))
+	for i := range conversions {
+		conversions[i].index = -1
+		for j, column := range header {
+			if column == conversions[i].column {
+				conversions[i].index = j
+				break
+			}
+		}
+		if conversions[i].index < 0 {
+			return invalidInput(fmt.Errorf(errCSVColumn, conversions[i].column))
+		}
+		names[i] = conversions[i].name
+	}
+
+	w := bufio.NewWriter(e.stdout)
+	defer w.Flush()
+	if err := writeCSVRecord(w, text, comma, names); err != nil {
+		return err
+	}
+
+	rows, bad := 0, 0
+	for {
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		text, line, err := records.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows++
+
+		cells := make([]string, len(conversions))
+		row, err := parseCSVRecord(text, line, comma)
+		if err != nil {
+			bad++
+			fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, cmdCSV, err)
+			if err := writeCSVRecord(w, text, comma, cells); err != nil {
+				return err
+			}
+			continue
+		}
+
+		failed := false
+		for i, conv := range conversions {
+			if conv.index >= len(row) {
+				failed = true
+				fmt.Fprintf(e.stderr, "%s %s: line %d: "+errCSVShortRow+"\n", programName, cmdCSV, line, conv.column)
+				continue
+			}
+			out, err := conv.command.each(row[conv.index])
+			if err != nil {
+				failed = true
+				fmt.Fprintf(e.stderr, "%s %s: line %d, column %s: %v\n", programName, cmdCSV, line, conv.column, err)
+			}
+			cells[i] = out
+		}
+		if failed {
+			bad++
+		}
+
+		if err := writeCSVRecord(w, text, comma, cells); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if bad > 0 {
+		return invalidInput(fmt.Errorf(errCSVBadRows, bad, rows))
+	}
+
+	return nil
+}
+
+// csvRecords splits CSV into the text of each record, as it was written, so
+// that records can be copied without being quoted afresh. Blank lines are
+// skipped, as encoding/csv skips them.
+type csvRecords struct {
+	in    *bufio.Reader
+	comma rune
+	// line is the number of lines read so far.
+	line int
+}
+
+// next returns the text of the next record, with its line ending, and the line
+// it starts on. A newline ends the record unless it is inside a quoted field.
+// TSV has no quoting, so every line of it is a record.
+func (r *csvRecords) next() (string, int, error) {
+
+	var (
+		b          strings.Builder
+		start      int
+		quoted     bool
+		fieldStart = true
+	)
+
+	for {
+		line, err := r.in.ReadString('\n')
+		if line == "" && err == io.EOF {
+			if b.Len() > 0 {
+				return b.String(), start, nil
+			}
+			return "", 0, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return "", 0, err
+		}
+		r.line++
+
+		if b.Len() == 0 && strings.TrimRight(line, "\r\n") == "" {
+			continue
+		}
+		if b.Len() == 0 {
+			start = r.line
+		}
+		b.WriteString(line)
+		if r.comma == '\t' {
+			return b.String(), start, nil
+		}
+
+		for i := 0; i < len(line); i++ {
+			c := rune(line[i])
+			switch {
+			case quoted && c == '"' && i+1 < len(line) && line[i+1] == '"':
+				i++
+			case quoted && c == '"':
+				quoted = false
+			case quoted:
+			case c == '"' && fieldStart:
+				quoted = true
+			}
+			fieldStart = !quoted && c == r.comma
+		}
+
+		if !quoted || err == io.EOF {
+			return b.String(), start, nil
+		}
+	}
+}
+
+// parseCSVRecord returns the fields of the text of a record that starts on the
+// given line. TSV is split on tabs, with no quoting, as csvRecords splits it
+// into lines.
+func parseCSVRecord(text string, line int, comma rune) ([]string, error) {
+
+	if comma == '\t' {
+		return strings.Split(strings.TrimRight(text, "\r\n"), "\t"), nil
+	}
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	fields, err := r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// Report the line in the whole file, not in the record.
+		parseErr.StartLine += line - 1
+		parseErr.Line += line - 1
+	}
+
+	return fields, err
+}
+
+// writeCSVRecord writes the text of a record with cells appended to it,
+// quoting only the cells as they need to be.
+func writeCSVRecord(w io.Writer, text string, comma rune, cells []string) error {
+
+	ending := "\n"
+	if strings.HasSuffix(text, "\r\n") {
+		ending = "\r\n"
+	}
+
+	var b strings.Builder
+	cw := csv.NewWriter(&b)
+	cw.Comma = comma
+	// Writing to a strings.Builder can't fail.
+	_ = cw.Write(cells)
+	cw.Flush()
+
+	_, err := io.WriteString(w, strings.TrimRight(text, "\r\n")+string(comma)+strings.TrimSuffix(b.String(), "\n")+ending)
+	return err
+}
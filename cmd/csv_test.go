@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSV(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "convert",
+			args:   []string{"-convert", "point:patp", "-convert", "point:patq:q"},
+			stdin:  "name,point\nzod,0\nsampel,1624961343\n",
+			code:   codeOK,
+			stdout: "name,point,point_patp,q\nzod,0,~zod,~zod\nsampel,1624961343,~sampel-palnet,~ronler-talpur\n",
+		},
+		{
+			name:   "quoting",
+			args:   []string{"-convert", "ship:patp2dec", "-"},
+			stdin:  "\"owner, name\",ship,note\n\"Smith, J\",~marzod,\"said \"\"hi\"\"\"\n\"two\nlines\",~nec,\n",
+			code:   codeOK,
+			stdout: "\"owner, name\",ship,note,ship_patp2dec\n\"Smith, J\",~marzod,\"said \"\"hi\"\"\",256\n\"two\nlines\",~nec,,1\n",
+		},
+		{
+			name:   "tsv",
+			args:   []string{"-tsv", "-convert", "ship:sein"},
+			stdin:  "ship\tnote\n~sampel-palnet\ta, b\n",
+			code:   codeOK,
+			stdout: "ship\tnote\tship_sein\n~sampel-palnet\ta, b\t~talpur\n",
+		},
+		{
+			name:   "quotes kept",
+			args:   []string{"-convert", "point:patp"},
+			stdin:  "\"name\",\"point\"\r\n\"zod\",\"0\"\r\n\r\n\"nec\",1\r\n",
+			code:   codeOK,
+			stdout: "\"name\",\"point\",point_patp\r\n\"zod\",\"0\",~zod\r\n\"nec\",1,~nec\r\n",
+		},
+		{
+			name:   "tsv quotes",
+			args:   []string{"-tsv", "-convert", "ship:patp2dec"},
+			stdin:  "ship\tnote\n~nec\t12\" tall\n~zod\t\"quoted\ttab\"\n",
+			code:   codeOK,
+			stdout: "ship\tnote\tship_patp2dec\n~nec\t12\" tall\t1\n~zod\t\"quoted\ttab\"\t0\n",
+		},
+		{
+			name:   "tsv unclosed quote",
+			args:   []string{"-tsv", "-convert", "ship:patp2dec"},
+			stdin:  "ship\tnote\n~nec\t\"open\n~zod\tclosed\"\n",
+			code:   codeOK,
+			stdout: "ship\tnote\tship_patp2dec\n~nec\t\"open\t1\n~zod\tclosed\"\t0\n",
+		},
+		{
+			name:   "bad rows",
+			args:   []string{"-convert", "point:patp"},
+			stdin:  "name,point\n\"two\nlines\",x\nb\nc,1\nd\"e,2\nf,3,extra\n",
+			code:   codeInvalidInput,
+			stdout: "name,point,point_patp\n\"two\nlines\",x,\nb,\nc,1,~nec\nd\"e,2,\nf,3,extra,~wes\n",
+			stderr: "urbit-gob csv: line 2, column point: invalid integer string: x\n" +
+				"urbit-gob csv: line 4: no column point in this row\n" +
+				"urbit-gob csv: parse error on line 6, column 2: bare \" in non-quoted-field\n" +
+				"urbit-gob csv: 3 of 5 rows could not be converted\n",
+		},
+		{
+			name:   "missing column",
+			args:   []string{"-convert", "ship:patp2dec"},
+			stdin:  "name,point\n",
+			code:   codeInvalidInput,
+			stderr: "urbit-gob csv: no column named ship\n",
+		},
+		{
+			name:   "no conversion",
+			args:   []string{"-"},
+			stdin:  "name,point\n",
+			code:   codeInvalidInput,
+			stderr: "urbit-gob csv: nothing to convert; use -convert column:command\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			code, stdout, stderr := runCLI(context.Background(), tt.stdin, append([]string{"csv"}, tt.args...)...)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, stdout)
+			assert.Equal(t, tt.stderr, stderr)
+		})
+	}
+}
+
+func TestCSVOutputJSON(t *testing.T) {
+
+	code, stdout, stderr := runCLI(context.Background(), "name,point\nzod,0\n", "--output", "json", "csv", "-convert", "point:patp")
+	assert.Equal(t, codeUsage, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "urbit-gob csv: --output json is not supported, since csv writes its own output\n", stderr)
+}
+
+func TestCSVConversions(t *testing.T) {
+
+	var testCases = []struct {
+		value string
+		err   string
+	}{
+		{value: "point:patp"},
+		{value: "point:patp:name"},
+		{value: "point", err: `invalid conversion "point": must be column:command or column:command:name`},
+		{value: ":patp", err: `invalid conversion ":patp": must be column:command or column:command:name`},
+		{value: "point:patz", err: "unknown command: patz"},
+		{value: "point:verify", err: "verify cannot convert a column; use a command that converts one value, such as patp"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.value, func(t *testing.T) {
+
+			var c csvConversions
+			err := c.Set(tt.value)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				assert.Len(t, c, 1)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -1,125 +1,157 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
-
-	"github.com/deelawn/urbit-gob/co"
+	"os/signal"
+	"runtime/debug"
 )
 
 const (
-	// Commands
-	cmdPatp     string = "patp"
-	cmdPatp2Dec string = "patp2dec"
-	cmdPatp2Hex string = "patp2hex"
-
-	cmdPatq     string = "patq"
-	cmdPatq2Dec string = "patq2dec"
-	cmdPatq2Hex string = "patq2hex"
-
-	cmdHex2Patp string = "hex2patp"
-	cmdHex2Patq string = "hex2patq"
-
-	cmdClan   string = "clan"
-	cmdSein   string = "sein"
-	cmdEqPatq string = "eqpatq"
-
-	cmdIsValidPat  string = "isvalidpat"
-	cmdIsValidPatp string = "isvalidpatp"
-	cmdIsValidPatq string = "isvalidpatq"
+	programName string = "urbit-gob"
 
 	// Exit codes
-	codeInsufficientArguments int = 1
-	codeInvalidCommand        int = 2
-	codeErrorReturned         int = 3
+	codeOK             int = 0
+	codeUsage          int = 1
+	codeInvalidCommand int = 2
+	codeInvalidInput   int = 3
+	codeFailure        int = 4
+	codeInterrupted    int = 130
 
 	// Others
-	minArgLen        int    = 2
-	usageCmdFmtStr   string = "    %-20s: %s\n"
-	errInvalidCmdStr string = "invalid command: %s\n"
-	errMissingEqArg  string = "missing second argument for equality check"
+	usageCmdFmtStr    string = "    %-20s: %s\n"
+	errUnknownCommand string = "unknown command: %s"
+	errHelpArgs       string = "help takes at most one command"
 )
 
+// version is set at build time with -ldflags "-X main.version=v1.2.3". If it
+// isn't, the version of the module from the build info is used.
+var version string
+
+// env is where a command reads its input and writes its output.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// output is the format of results, outputText or outputJSON.
+	output string
+	// piped is set if stdin is a pipe or a file rather than a terminal.
+	piped bool
+}
+
 func main() {
 
-	flag.Usage = func() {
-		fmt.Printf("Usage: %s COMMAND args...\n\n", os.Args[0])
-		fmt.Printf("Valid commands:\n\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp, "converts a number to a @p-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp2Dec, "converts a @p-encoded string to a decimal-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatp2Hex, "converts a @p-encoded string to a hex-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq, "converts a number to a @q-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq2Dec, "converts a @q-encoded string to a decimal-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdPatq2Hex, "converts a @q-encoded string to a hex-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdHex2Patp, "converts a hex-encoded string to a @p-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdHex2Patq, "converts a hex-encoded string to a @q-encoded string\n")
-		fmt.Printf(usageCmdFmtStr, cmdClan, "determines the ship class of a @p value\n")
-		fmt.Printf(usageCmdFmtStr, cmdSein, "determines the parent of a @p value\n")
-		fmt.Printf(usageCmdFmtStr, cmdEqPatq, "performs an equality comparison on @q values\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPat, "weakly checks if a string is a valid @p or @q value\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPatp, "validates a @p string\n")
-		fmt.Printf(usageCmdFmtStr, cmdIsValidPatq, "validates a @q string\n")
+	// The first interrupt cancels the command, which lets commands like
+	// verify save their progress; the second one kills the process.
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	code := run(ctx, os.Args[1:], env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, piped: isPiped(os.Stdin)})
+	cancel()
+	os.Exit(code)
+}
+
+// run runs the command line given by args and returns the exit code.
+func run(ctx context.Context, args []string, e env) int {
+
+	global := flag.NewFlagSet(programName, flag.ContinueOnError)
+	global.SetOutput(ioutil.Discard)
+	showVersion := global.Bool("version", false, "print the version and exit")
+	global.StringVar(&e.output, "output", outputText, "the format of results, text or json")
+
+	if err := global.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			usage(e.stdout, commands())
+			return codeOK
+		}
+		fmt.Fprintf(e.stderr, "%s: %v\n", programName, err)
+		usage(e.stderr, commands())
+		return codeUsage
+	}
+
+	if err := checkOutput(e.output); err != nil {
+		fmt.Fprintf(e.stderr, "%s: %v\n", programName, err)
+		return codeUsage
 	}
 
-	flag.Parse()
+	if *showVersion {
+		fmt.Fprintln(e.stdout, programName, versionString())
+		return codeOK
+	}
 
-	var (
-		result interface{}
-		err    error
-	)
+	args = global.Args()
+	cmds := commands()
+	if len(args) == 0 {
+		usage(e.stderr, cmds)
+		return codeUsage
+	}
 
-	args := flag.Args()
-	if len(args) < minArgLen {
-		flag.Usage()
-		os.Exit(codeInsufficientArguments)
+	if args[0] == cmdHelp {
+		return help(cmds, args[1:], e)
 	}
 
-	switch args[0] {
-
-	case cmdPatp:
-		result, err = co.Patp(args[1])
-	case cmdPatp2Dec:
-		result, err = co.Patp2Dec(args[1])
-	case cmdPatp2Hex:
-		result, err = co.Patp2Hex(args[1])
-	case cmdPatq:
-		result, err = co.Patq(args[1])
-	case cmdPatq2Dec:
-		result, err = co.Patq2Dec(args[1])
-	case cmdPatq2Hex:
-		result, err = co.Patq2Hex(args[1])
-	case cmdHex2Patp:
-		result, err = co.Hex2Patp(args[1])
-	case cmdHex2Patq:
-		result, err = co.Hex2Patq(args[1])
-	case cmdClan:
-		result, err = co.Clan(args[1])
-	case cmdSein:
-		result, err = co.Sein(args[1])
-	case cmdIsValidPat:
-		result = co.IsValidPat(args[1])
-	case cmdIsValidPatp:
-		result = co.IsValidPatp(args[1])
-	case cmdIsValidPatq:
-		result = co.IsValidPatq(args[1])
-	case cmdEqPatq:
-		if len(args) < 3 {
-			fmt.Println(errMissingEqArg)
-			flag.Usage()
-			os.Exit(codeInsufficientArguments)
-		}
-		result, err = co.EqPatq(args[1], args[2])
+	cmd := lookup(cmds, args[0])
+	if cmd == nil {
+		fmt.Fprintf(e.stderr, "%s: "+errUnknownCommand+"\n", programName, args[0])
+		usage(e.stderr, cmds)
+		return codeInvalidCommand
+	}
+
+	return cmd.execute(ctx, args[1:], e)
+}
+
+func usage(w io.Writer, cmds []*command) {
+
+	fmt.Fprintf(w, "Usage: %s [--version] [--output text|json] COMMAND [options] args...\n\n", programName)
+	fmt.Fprintf(w, "Valid commands:\n\n")
+	for _, cmd := range cmds {
+		fmt.Fprintf(w, usageCmdFmtStr, cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, usageCmdFmtStr, cmdHelp, "shows how to use a command")
+	fmt.Fprintf(w, "\nRun '%s help COMMAND' for the arguments and options of a command.\n", programName)
+}
+
+func help(cmds []*command, args []string, e env) int {
+
+	switch len(args) {
+	case 0:
+		usage(e.stdout, cmds)
+		return codeOK
+	case 1:
 	default:
-		fmt.Printf(errInvalidCmdStr, args[0])
-		flag.Usage()
-		os.Exit(codeInvalidCommand)
+		fmt.Fprintf(e.stderr, "%s: %s\n", programName, errHelpArgs)
+		return codeUsage
+	}
+
+	cmd := lookup(cmds, args[0])
+	if cmd == nil {
+		fmt.Fprintf(e.stderr, "%s: "+errUnknownCommand+"\n", programName, args[0])
+		return codeInvalidCommand
+	}
+
+	cmd.usage(e.stdout)
+	return codeOK
+}
+
+func versionString() string {
+
+	if version != "" {
+		return version
 	}
 
-	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(codeErrorReturned)
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
 	}
 
-	fmt.Println(result)
+	return "(devel)"
 }
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/deelawn/urbit-gob/ob"
+	"github.com/stretchr/testify/assert"
+)
+
+// runCLI runs a command line and returns its exit code and output. Unless
+// stdin is empty, it is piped.
+func runCLI(ctx context.Context, stdin string, args ...string) (int, string, string) {
+
+	var stdout, stderr bytes.Buffer
+	e := env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr, piped: stdin != ""}
+	code := run(ctx, args, e)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "patp", args: []string{"patp", "0"}, code: codeOK, stdout: "~zod\n"},
+		{name: "clan", args: []string{"clan", "~marzod"}, code: codeOK, stdout: "star\n"},
+		{name: "predicate", args: []string{"isvalidpatp", "~doznec"}, code: codeOK, stdout: "false\n"},
+		{name: "eqpatq", args: []string{"eqpatq", "~dozzod-sampel", "~sampel"}, code: codeOK, stdout: "true\n"},
+		{
+			name:   "invalid input",
+			args:   []string{"patp2dec", "~foo"},
+			code:   codeInvalidInput,
+			stderr: "urbit-gob patp2dec: invalid @p: ~foo\n",
+		},
+		{
+			name:   "missing argument",
+			args:   []string{"patp"},
+			code:   codeUsage,
+			stderr: "urbit-gob patp: expects 1 argument, got 0\nUsage: urbit-gob patp [options] <number> | -",
+		},
+		{
+			name:   "extra argument",
+			args:   []string{"eqpatq", "~sampel", "~sampel", "~sampel"},
+			code:   codeUsage,
+			stderr: "urbit-gob eqpatq: expects 2 arguments, got 3",
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"patp", "-samples", "2", "0"},
+			code:   codeUsage,
+			stderr: "urbit-gob patp: flag provided but not defined: -samples",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"patz", "0"},
+			code:   codeInvalidCommand,
+			stderr: "urbit-gob: unknown command: patz\nUsage: urbit-gob",
+		},
+		{name: "no command", args: nil, code: codeUsage, stderr: "Usage: urbit-gob"},
+		{name: "version", args: []string{"--version"}, code: codeOK, stdout: "urbit-gob (devel)\n"},
+		{name: "usage", args: []string{"help"}, code: codeOK, stdout: "Usage: urbit-gob"},
+		{name: "global help flag", args: []string{"-h"}, code: codeOK, stdout: "Usage: urbit-gob"},
+		{
+			name:   "command help",
+			args:   []string{"help", "vectors"},
+			code:   codeOK,
+			stdout: "Usage: urbit-gob vectors [options] <seed>\n\nWrites conformance vectors for a random seed.\n",
+		},
+		{name: "command help flag", args: []string{"verify", "-h"}, code: codeOK, stdout: "  -checkpoint file\n"},
+		{
+			name:   "help for unknown command",
+			args:   []string{"help", "patz"},
+			code:   codeInvalidCommand,
+			stderr: "urbit-gob: unknown command: patz\n",
+		},
+		{
+			name:   "command flag",
+			args:   []string{"vectors", "-samples", "0", "1"},
+			code:   codeOK,
+			stdout: "{\n  \"version\": 1,\n  \"seed\": 1,\n",
+		},
+		{
+			name:   "verify",
+			args:   []string{"verify", "-chunk", "4096", "0xff00", "0x1ffff"},
+			code:   codeOK,
+			stdout: "all 65792 values in [0xff00, 0x1ffff] verified\n",
+		},
+		{
+			name:   "verify the top value",
+			args:   []string{"verify", "0xffffffffffffff00", "0xffffffffffffffff"},
+			code:   codeOK,
+			stdout: "all 256 values in [0xffffffffffffff00, 0xffffffffffffffff] verified\n",
+		},
+		{
+			name:   "verify backwards",
+			args:   []string{"verify", "10", "5"},
+			code:   codeInvalidInput,
+			stderr: "urbit-gob verify: invalid range [10, 5]: start is after end\n",
+		},
+		{
+			name:   "checkvectors",
+			args:   []string{"checkvectors", filepath.Join("..", "conformance", "testdata", "vectors.json")},
+			code:   codeOK,
+			stdout: "all 71 vectors match\n",
+		},
+		{
+			name:   "checkvectors without a file",
+			args:   []string{"checkvectors", "does-not-exist.json"},
+			code:   codeInvalidInput,
+			stderr: "urbit-gob checkvectors: open does-not-exist.json: no such file or directory\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			code, stdout, stderr := runCLI(context.Background(), "", tt.args...)
+			assert.Equal(t, tt.code, code)
+			if tt.stdout == "" {
+				assert.Empty(t, stdout)
+			} else {
+				assert.Contains(t, stdout, tt.stdout)
+			}
+			if tt.stderr == "" {
+				assert.Empty(t, stderr)
+			} else {
+				assert.Contains(t, stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "urbit-gob")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	checkpoint := filepath.Join(dir, "checkpoint.json")
+
+	// A canceled run saves where it got to, and the next run resumes from it.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	code, _, stderr := runCLI(ctx, "", "verify", "-checkpoint", checkpoint, "-chunk", "4096", "0", "0xfffff")
+	assert.Equal(t, codeInterrupted, code)
+	assert.Equal(t, "urbit-gob verify: context canceled\n", stderr)
+
+	saved, err := loadCheckpoint(checkpoint, ob.NewVerifyState(0, 0xfffff))
+	assert.NoError(t, err)
+	assert.False(t, saved.Done())
+
+	code, stdout, _ := runCLI(context.Background(), "", "verify", "-checkpoint", checkpoint, "-chunk", "4096", "0", "0xfffff")
+	assert.Equal(t, codeOK, code)
+	assert.Equal(t, "all 1048576 values in [0x0, 0xfffff] verified\n", stdout)
+
+	code, _, stderr = runCLI(context.Background(), "", "verify", "-checkpoint", checkpoint, "0", "0x1ff")
+	assert.Equal(t, codeInvalidInput, code)
+	assert.Contains(t, stderr, "is for [0, 1048575], not [0, 511]")
+}
+
+func TestExitCode(t *testing.T) {
+
+	var testCases = []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "input", err: invalidInput(errors.New("bad")), code: codeInvalidInput},
+		{name: "failure", err: errors.New("broken"), code: codeFailure},
+		{name: "interrupted", err: context.Canceled, code: codeInterrupted},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			assert.Equal(t, tt.code, exitCode(tt.err))
+		})
+	}
+
+	assert.NoError(t, invalidInput(nil))
+}
+
+func TestJSONOutput(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+	}{
+		{
+			name:   "patp",
+			args:   []string{"patp", "1624961343"},
+			code:   codeOK,
+			stdout: `{"input":"1624961343","result":"~sampel-palnet","class":"planet","error":null}`,
+		},
+		{
+			name:   "patq2dec",
+			args:   []string{"patq2dec", "~marzod"},
+			code:   codeOK,
+			stdout: `{"input":"~marzod","result":"256","class":"star","error":null}`,
+		},
+		{
+			name:   "hex2patq",
+			args:   []string{"hex2patq", "010000"},
+			code:   codeOK,
+			stdout: `{"input":"010000","result":"~doznec-dozzod","class":"planet","error":null}`,
+		},
+		{
+			name:   "predicate",
+			args:   []string{"isvalidpatp", "~doznec"},
+			code:   codeOK,
+			stdout: `{"input":"~doznec","result":false,"class":"galaxy","error":null}`,
+		},
+		{
+			name:   "invalid input",
+			args:   []string{"clan", "~foo"},
+			code:   codeInvalidInput,
+			stdout: `{"input":"~foo","result":null,"class":null,"error":"invalid @p: ~foo"}`,
+		},
+		{
+			name:   "several arguments",
+			args:   []string{"eqpatq", "~dozzod-sampel", "~sampel"},
+			code:   codeOK,
+			stdout: `{"input":"~dozzod-sampel ~sampel","result":true,"class":null,"error":null}`,
+		},
+		{
+			name:   "structured result",
+			args:   []string{"verify", "0", "0xff"},
+			code:   codeOK,
+			stdout: `{"input":"0 0xff","result":{"start":0,"end":255,"next":256,"complete":true,"failures":0},"class":null,"error":null}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			code, stdout, stderr := runCLI(context.Background(), "", append([]string{"--output", "json"}, tt.args...)...)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout+"\n", stdout)
+			assert.Empty(t, stderr)
+		})
+	}
+
+	code, _, stderr := runCLI(context.Background(), "", "--output", "yaml", "patp", "0")
+	assert.Equal(t, codeUsage, code)
+	assert.Equal(t, "urbit-gob: invalid output format: yaml (must be text or json)\n", stderr)
+}
+
+func TestBatch(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "piped",
+			args:   []string{"patp"},
+			stdin:  "0\n1624961343\n",
+			code:   codeOK,
+			stdout: "~zod\n~sampel-palnet\n",
+		},
+		{
+			name:   "dash",
+			args:   []string{"clan", "-"},
+			stdin:  "~zod\r\n  ~marzod  \n\n~sampel-palnet",
+			code:   codeOK,
+			stdout: "galaxy\nstar\nplanet\n",
+		},
+		{
+			name:   "errors",
+			args:   []string{"patp2dec", "-"},
+			stdin:  "~zod\n~foo\n\n~bar\n~marzod\n",
+			code:   codeInvalidInput,
+			stdout: "0\n256\n",
+			stderr: "urbit-gob patp2dec: line 2: invalid @p: ~foo\nurbit-gob patp2dec: line 4: invalid @p: ~bar\n",
+		},
+		{
+			name:   "json",
+			args:   []string{"--output", "json", "isvalidpatp"},
+			stdin:  "~zod\n~foo\n",
+			code:   codeOK,
+			stdout: `{"input":"~zod","result":true,"class":"galaxy","error":null}` + "\n" + `{"input":"~foo","result":false,"class":null,"error":null}` + "\n",
+		},
+		{
+			name:   "json errors",
+			args:   []string{"--output", "json", "patq", "-"},
+			stdin:  "x\n1\n",
+			code:   codeInvalidInput,
+			stdout: `{"input":"x","result":null,"class":null,"error":"invalid integer string: x"}` + "\n" + `{"input":"1","result":"~nec","class":"galaxy","error":null}` + "\n",
+		},
+		{
+			name:   "argument",
+			args:   []string{"patp", "1"},
+			stdin:  "0\n",
+			code:   codeOK,
+			stdout: "~nec\n",
+		},
+		{
+			name:   "not per value",
+			args:   []string{"vectors", "-"},
+			stdin:  "1\n",
+			code:   codeInvalidInput,
+			stderr: "urbit-gob vectors: strconv.ParseInt: parsing \"-\": invalid syntax\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			code, stdout, stderr := runCLI(context.Background(), tt.stdin, tt.args...)
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, tt.stdout, stdout)
+			assert.Equal(t, tt.stderr, stderr)
+		})
+	}
+}
+
+func TestBatchWorkers(t *testing.T) {
+
+	var in, want strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&in, "%d\n", i*7919)
+		p, err := co.Patp(strconv.Itoa(i * 7919))
+		assert.NoError(t, err)
+		fmt.Fprintf(&want, "%s\n", p)
+	}
+
+	code, stdout, stderr := runCLI(context.Background(), in.String(), "patp", "-workers", "8", "-")
+	assert.Equal(t, codeOK, code)
+	assert.Equal(t, want.String(), stdout)
+	assert.Empty(t, stderr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	code, _, stderr = runCLI(ctx, in.String(), "patp", "-")
+	assert.Equal(t, codeInterrupted, code)
+	assert.Equal(t, "urbit-gob patp: context canceled\n", stderr)
+}
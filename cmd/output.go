@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+)
+
+const (
+	// Output formats
+	outputText string = "text"
+	outputJSON string = "json"
+
+	errOutputFormat string = "invalid output format: %s (must be %s or %s)"
+	errOutputRaw    string = "--output %s is not supported, since %s writes its own output"
+)
+
+// inputKind is what the argument of a command is, which is how the class of
+// the ship it names is found.
+type inputKind int
+
+const (
+	inputOther inputKind = iota
+	inputNumber
+	inputPatp
+	inputPatq
+	inputHex
+)
+
+// usage describes an argument of the kind.
+func (k inputKind) usage() string {
+
+	switch k {
+	case inputNumber:
+		return "<number>"
+	case inputPatp:
+		return "<@p>"
+	case inputPatq:
+		return "<@q>"
+	case inputHex:
+		return "<hex>"
+	}
+
+	return "<value>"
+}
+
+// class returns the class of the ship that arg names, if it is a valid input of
+// the kind.
+func (k inputKind) class(arg string) (string, bool) {
+
+	var (
+		name string
+		err  error
+	)
+
+	switch k {
+	case inputNumber:
+		name, err = co.Patp(arg)
+	case inputPatp:
+		name = arg
+	case inputPatq:
+		var dec string
+		if dec, err = co.Patq2Dec(arg); err == nil {
+			name, err = co.Patp(dec)
+		}
+	case inputHex:
+		name, err = co.Hex2Patp(arg)
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+
+	clan, err := co.Clan(name)
+	return clan, err == nil
+}
+
+/*
+record is the JSON output of a command for one input. Its fields are always
+present, so that scripts can rely on them: result and error are null when the
+command failed or succeeded respectively, and class is null when the input
+doesn't name a ship.
+*/
+type record struct {
+	Input  string      `json:"input"`
+	Result interface{} `json:"result"`
+	Class  *string     `json:"class"`
+	Error  *string     `json:"error"`
+}
+
+func newRecord(kind inputKind, args []string, result interface{}, err error) record {
+
+	r := record{Input: strings.Join(args, " ")}
+	if err != nil {
+		msg := err.Error()
+		r.Error = &msg
+	} else {
+		r.Result = result
+	}
+
+	if len(args) == 1 {
+		if clan, ok := kind.class(args[0]); ok {
+			r.Class = &clan
+		}
+	}
+
+	return r
+}
+
+// writeResult writes the result of a command, or its error, in the output
+// format. Text results go to stdout and errors to stderr, while JSON records
+// always go to stdout, one per line.
+func writeResult(e env, c *command, args []string, result interface{}, err error) {
+
+	if e.output == outputJSON {
+		// A record always encodes, since results are strings, bools or
+		// structs of them.
+		_ = json.NewEncoder(e.stdout).Encode(newRecord(c.input, args, result, err))
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(e.stderr, "%s %s: %v\n", programName, c.name, err)
+		return
+	}
+
+	fmt.Fprintln(e.stdout, result)
+}
+
+// checkOutput returns an error unless format is a valid output format.
+func checkOutput(format string) error {
+
+	switch format {
+	case outputText, outputJSON:
+		return nil
+	}
+
+	return fmt.Errorf(errOutputFormat, format, outputText, outputJSON)
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	"golang.org/x/term"
+)
+
+const (
+	cmdRepl string = "repl"
+
+	replPrompt string = "> "
+
+	// maxCompletions is the most choices Tab lists.
+	maxCompletions int = 64
+
+	errReplVariable string = "no variable %s"
+	errReplValue    string = "%s is not a number, @p, @q or hex"
+	errReplArgs     string = "%s takes one value"
+	errReplSyntax   string = "expected a value, or a command and a value"
+
+	replHelp string = `Type a number, @p, @q or hex to see every way to read it, or a command and a
+value, such as sein ~sampel-palnet, to run the command.
+
+Every result is kept in a variable: $1, $2 and so on, with $ for the latest.
+name = ... keeps a result as $name as well. Numbers are kept in decimal.
+
+Tab completes commands and the syllables of names. Other commands:
+
+    vars                : lists the variables
+    help                : shows this help
+    exit                : leaves, like Ctrl-D`
+)
+
+func newRepl() *command {
+
+	cmd := newCommand(cmdRepl, "", "starts an interactive session that shows every interpretation of a value", 0, 0)
+	cmd.detail = `Line editing, history and tab completion are available when stdin is a
+terminal. Otherwise each line of stdin is read as if it were typed, without a
+prompt. Type help in the session for more.`
+	cmd.raw = true
+	cmd.run = func(ctx context.Context, e env, args []string) (interface{}, error) {
+
+		r := newSession()
+		if f, ok := e.stdin.(*os.File); ok && !e.piped && term.IsTerminal(int(f.Fd())) {
+			return nil, r.interactive(f, e.stdout)
+		}
+
+		r.out, r.errOut = e.stdout, e.stderr
+		scanner := bufio.NewScanner(e.stdin)
+		for scanner.Scan() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if r.handle(scanner.Text()) {
+				break
+			}
+		}
+
+		return nil, scanner.Err()
+	}
+
+	return cmd
+}
+
+// session is the state of a REPL: its variables and where it writes.
+type session struct {
+	cmds    []*command
+	results []string
+	named   map[string]string
+	out     io.Writer
+	errOut  io.Writer
+}
+
+func newSession() *session {
+
+	s := &session{named: map[string]string{}}
+	for _, cmd := range commands() {
+		if cmd.each != nil {
+			s.cmds = append(s.cmds, cmd)
+		}
+	}
+
+	return s
+}
+
+// interactive runs the session on a terminal until it is left.
+func (s *session) interactive(f *os.File, w io.Writer) error {
+
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{f, w}, replPrompt)
+	if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+		_ = t.SetSize(width, height)
+	}
+	t.AutoCompleteCallback = s.complete
+	s.out, s.errOut = t, t
+
+	fmt.Fprintf(t, "%s %s: type a number, @p, @q or hex, or help\n", programName, versionString())
+	for {
+		line, err := t.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if s.handle(line) {
+			return nil
+		}
+	}
+}
+
+// handle evaluates a line and reports whether the session should end.
+func (s *session) handle(line string) bool {
+
+	line = strings.TrimSpace(line)
+	switch line {
+	case "":
+		return false
+	case "exit", "quit":
+		return true
+	case "help":
+		fmt.Fprintln(s.out, replHelp)
+		return false
+	case "vars":
+		s.listVariables()
+		return false
+	}
+
+	if err := s.evaluate(line); err != nil {
+		fmt.Fprintf(s.errOut, "error: %v\n", err)
+	}
+
+	return false
+}
+
+func (s *session) evaluate(line string) error {
+
+	name := ""
+	if i := strings.Index(line, "="); i > 0 && isVariableName(strings.TrimSpace(line[:i])) {
+		name = strings.TrimSpace(line[:i])
+		line = line[i+1:]
+	}
+
+	words := strings.Fields(line)
+	for i, word := range words {
+		value, err := s.expand(word)
+		if err != nil {
+			return err
+		}
+		words[i] = value
+	}
+
+	switch {
+	case len(words) == 0:
+		return errors.New(errReplSyntax)
+	case lookup(s.cmds, words[0]) != nil:
+		cmd := lookup(s.cmds, words[0])
+		if len(words) != 2 {
+			return fmt.Errorf(errReplArgs, cmd.name)
+		}
+		result, err := cmd.each(words[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "%s = %s\n", s.keep(name, result), result)
+		return nil
+	case len(words) > 1:
+		return errors.New(errReplSyntax)
+	}
+
+	readings := interpret(words[0])
+	if len(readings) == 0 {
+		return fmt.Errorf(errReplValue, words[0])
+	}
+
+	for i, reading := range readings {
+		dec := reading.Atom.String()
+		if i > 0 {
+			// Only the first reading is kept under the name.
+			name = ""
+		}
+		fmt.Fprintf(s.out, "%s = %s (read as @%s)\n", s.keep(name, dec), dec, reading.Aura)
+		s.describe(reading.Atom)
+	}
+
+	return nil
+}
+
+// describe writes every form of a number.
+func (s *session) describe(atom *big.Int) {
+
+	dec := atom.String()
+	p, perr := co.Patp(dec)
+	q, _ := co.Patq(dec)
+
+	fmt.Fprintf(s.out, "  %-6s 0x%s\n", "hex", atom.Text(16))
+	fmt.Fprintf(s.out, "  %-6s %s\n", "@p", p)
+	fmt.Fprintf(s.out, "  %-6s %s\n", "@q", q)
+	if perr != nil {
+		return
+	}
+	if clan, err := co.Clan(p); err == nil {
+		fmt.Fprintf(s.out, "  %-6s %s\n", "class", clan)
+	}
+	if sein, err := co.Sein(p); err == nil {
+		fmt.Fprintf(s.out, "  %-6s %s\n", "sein", sein)
+	}
+}
+
+// interpret returns every way to read text as a number: as any atom literal
+// Nuck knows, and as plain decimal or hex. Digits alone are read as both, with
+// the hex reading right after the decimal one.
+func interpret(text string) []co.Reading {
+
+	var readings []co.Reading
+	add := func(at int, aura string, atom *big.Int) {
+		if indexAura(readings, aura) >= 0 {
+			return
+		}
+		readings = append(readings, co.Reading{})
+		copy(readings[at+1:], readings[at:])
+		readings[at] = co.Reading{Aura: aura, Atom: atom}
+	}
+
+	if lit, err := co.Nuck(text); err == nil {
+		add(len(readings), lit.Aura, lit.Atom)
+		for _, alt := range lit.Alternatives {
+			add(len(readings), alt.Aura, alt.Atom)
+		}
+	}
+
+	if v, ok := big.NewInt(0).SetString(text, 10); ok && v.Sign() >= 0 && isDigits(text, false) {
+		add(len(readings), "ud", v)
+	}
+
+	hex := strings.TrimPrefix(text, "0x")
+	if v, ok := big.NewInt(0).SetString(hex, 16); ok && isDigits(hex, true) {
+		if ud := indexAura(readings, "ud"); ud >= 0 {
+			add(ud+1, "ux", v)
+		} else {
+			add(len(readings), "ux", v)
+		}
+	}
+
+	return readings
+}
+
+func indexAura(readings []co.Reading, aura string) int {
+
+	for i, r := range readings {
+		if r.Aura == aura {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isDigits(text string, hex bool) bool {
+
+	for _, c := range text {
+		switch {
+		case c >= '0' && c <= '9':
+		case hex && (c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'):
+		default:
+			return false
+		}
+	}
+
+	return text != ""
+}
+
+func isVariableName(name string) bool {
+
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return name != ""
+}
+
+// expand returns the value of a word that names a variable, or the word
+// itself.
+func (s *session) expand(word string) (string, error) {
+
+	if !strings.HasPrefix(word, "$") {
+		return word, nil
+	}
+
+	ref := word[1:]
+	if ref == "" && len(s.results) > 0 {
+		return s.results[len(s.results)-1], nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(s.results) {
+		return s.results[n-1], nil
+	}
+	if value, ok := s.named[ref]; ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf(errReplVariable, word)
+}
+
+// keep stores a result, and under name too if it isn't empty, and returns the
+// variable it can be found in.
+func (s *session) keep(name, value string) string {
+
+	s.results = append(s.results, value)
+	if name != "" {
+		s.named[name] = value
+		return "$" + name
+	}
+
+	return "$" + strconv.Itoa(len(s.results))
+}
+
+func (s *session) listVariables() {
+
+	for i, value := range s.results {
+		fmt.Fprintf(s.out, "$%d = %s\n", i+1, value)
+	}
+
+	names := make([]string, 0, len(s.named))
+	for name := range s.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(s.out, "$%s = %s\n", name, s.named[name])
+	}
+}
+
+/*
+complete is the term.Terminal.AutoCompleteCallback of the session. On Tab it
+completes the command at the start of the line, or the syllable being typed in
+a name: a prefix at the start of each word, or the suffix after it, or either
+at the start of a name, where a lone suffix is a galaxy. If the completion is
+ambiguous, it lists the choices, unless there are too many.
+*/
+func (s *session) complete(line string, pos int, key rune) (string, int, bool) {
+
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	start := pos
+	for start > 0 && isLetter(line[start-1]) {
+		start--
+	}
+	word := line[start:pos]
+
+	var candidates []string
+	switch {
+	case start == 0:
+		for _, cmd := range s.cmds {
+			candidates = append(candidates, cmd.name)
+		}
+		candidates = append(candidates, "exit", "help", "vars")
+	case line[start-1] == '~' || line[start-1] == '-':
+		switch {
+		case len(word) < 3:
+			candidates = append(candidates, co.Prefixes...)
+			if line[start-1] == '~' {
+				candidates = append(candidates, co.Suffixes...)
+			}
+		case len(word) < 6 && contains(co.Prefixes, word[:3]):
+			for _, suffix := range co.Suffixes {
+				candidates = append(candidates, word[:3]+suffix)
+			}
+		}
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	if len(matches) == 1 && start == 0 {
+		// A command is always followed by its value.
+		common += " "
+	}
+
+	if len(common) == len(word) {
+		switch {
+		case len(matches) > maxCompletions:
+			fmt.Fprintf(s.out, "%d choices\n", len(matches))
+		case len(matches) > 1:
+			sort.Strings(matches)
+			fmt.Fprintln(s.out, strings.Join(matches, " "))
+		}
+		return "", 0, false
+	}
+
+	return line[:start] + common + line[pos:], start + len(common), true
+}
+
+func isLetter(c byte) bool {
+
+	return c >= 'a' && c <= 'z'
+}
+
+func contains(list []string, s string) bool {
+
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepl(t *testing.T) {
+
+	stdin := "1624961343\n\nx = sein ~sampel-palnet\npatp2dec $x\nclan $\n$9\nfoo bar\nvars\nexit\npatp 0\n"
+	code, stdout, stderr := runCLI(context.Background(), stdin, "repl")
+
+	assert.Equal(t, codeOK, code)
+	assert.Equal(t, `$1 = 1624961343 (read as @ud)
+  hex    0x60daf13f
+  @p     ~sampel-palnet
+  @q     ~ronler-talpur
+  class  planet
+  sein   ~talpur
+$2 = 95103095619 (read as @ux)
+  hex    0x1624961343
+  @p     ~dozlup-sicsug-difmut
+  @q     ~dozlup-livryg-modnut
+  class  moon
+  sein   ~sicsug-difmut
+$x = ~talpur
+$4 = 61759
+$1 = 1624961343
+$2 = 95103095619
+$3 = ~talpur
+$4 = 61759
+$x = ~talpur
+`, stdout)
+	assert.Equal(t, "error: invalid @p: 61759\nerror: no variable $9\nerror: expected a value, or a command and a value\n", stderr)
+}
+
+func TestReplOutputJSON(t *testing.T) {
+
+	code, stdout, stderr := runCLI(context.Background(), "patp 0\n", "--output", "json", "repl")
+	assert.Equal(t, codeUsage, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "urbit-gob repl: --output json is not supported, since repl writes its own output\n", stderr)
+}
+
+func TestInterpret(t *testing.T) {
+
+	var testCases = []struct {
+		text  string
+		auras []string
+		atoms []string
+	}{
+		{text: "1624961343", auras: []string{"ud", "ux"}, atoms: []string{"1624961343", "95103095619"}},
+		{text: "10", auras: []string{"ud", "ux"}, atoms: []string{"10", "16"}},
+		{text: "1024", auras: []string{"ud", "ux"}, atoms: []string{"1024", "4132"}},
+		{text: "0x10", auras: []string{"ux"}, atoms: []string{"16"}},
+		{text: "1.624.961.343", auras: []string{"ud"}, atoms: []string{"1624961343"}},
+		{text: "0x60daf13f", auras: []string{"ux"}, atoms: []string{"1624961343"}},
+		{text: "0x60da.f13f", auras: []string{"ux"}, atoms: []string{"1624961343"}},
+		{text: "ff", auras: []string{"ux"}, atoms: []string{"255"}},
+		{text: "~ronler-talpur", auras: []string{"p", "q"}, atoms: []string{"2742534178", "1624961343"}},
+		{text: "~marzod", auras: []string{"p", "q"}, atoms: []string{"256", "256"}},
+		{text: "~foo"},
+		{text: "0xfg"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.text, func(t *testing.T) {
+
+			var auras, atoms []string
+			for _, r := range interpret(tt.text) {
+				auras = append(auras, r.Aura)
+				atoms = append(atoms, r.Atom.String())
+			}
+			assert.Equal(t, tt.auras, auras)
+			assert.Equal(t, tt.atoms, atoms)
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		line   string
+		key    rune
+		ok     bool
+		result string
+		listed string
+	}{
+		{name: "command", line: "cl", key: '\t', ok: true, result: "clan "},
+		{name: "command prefix", line: "patp", key: '\t', listed: "patp patp2dec patp2hex\n"},
+		{name: "galaxy", line: "clan ~zo", key: '\t', ok: true, result: "clan ~zod"},
+		{name: "prefix", line: "~sampel-pa", key: '\t', listed: "pac pad pag pal pan par pas pat\n"},
+		{name: "suffix", line: "~sampel-palz", key: '\t', ok: true, result: "~sampel-palzod"},
+		{name: "suffixes", line: "~sampel-palny", key: '\t', listed: "palnyd palnyl palnym palnyr palnys palnyt palnyx\n"},
+		{name: "no such prefix", line: "~sampel-palnet-x", key: '\t'},
+		{name: "too many", line: "~sampel-pal", key: '\t', listed: "256 choices\n"},
+		{name: "not a syllable", line: "~zodz", key: '\t'},
+		{name: "other key", line: "cl", key: 'a'},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var out bytes.Buffer
+			s := newSession()
+			s.out = &out
+
+			result, pos, ok := s.complete(tt.line, len(tt.line), tt.key)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.result, result)
+				assert.Equal(t, len(tt.result), pos)
+			}
+			assert.Equal(t, tt.listed, out.String())
+		})
+	}
+}
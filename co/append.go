@@ -0,0 +1,58 @@
+package co
+
+import (
+	"math/bits"
+
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// AppendPatp appends the @p-encoded form of v to dst and returns the extended
+// buffer, like strconv.AppendInt. It does not allocate unless dst needs to
+// grow.
+func AppendPatp(dst []byte, v uint64) []byte {
+
+	return appendWords(dst, ob.Fein64(v))
+}
+
+// AppendPatq appends the @q-encoded form of v to dst and returns the extended
+// buffer, like strconv.AppendInt. It does not allocate unless dst needs to
+// grow.
+func AppendPatq(dst []byte, v uint64) []byte {
+
+	return appendWords(dst, v)
+}
+
+// appendWords spells out v one 16-bit word at a time, most significant first.
+// A value of a single byte is a lone suffix, and every word of a longer value
+// is a prefix and a suffix, so a leading odd byte gets the prefix doz. This is
+// how both @p and @q render anything that fits in 64 bits.
+func appendWords(dst []byte, v uint64) []byte {
+
+	dst = append(dst, '~')
+	if v <= 0xff {
+		return appendSuffix(dst, byte(v))
+	}
+
+	for i := (bits.Len64(v)+15)/16 - 1; i >= 0; i-- {
+		word := uint16(v >> uint(16*i))
+		dst = appendPrefix(dst, byte(word>>8))
+		dst = appendSuffix(dst, byte(word))
+		if i > 0 {
+			dst = append(dst, '-')
+		}
+	}
+
+	return dst
+}
+
+func appendPrefix(dst []byte, b byte) []byte {
+
+	i := 3 * int(b)
+	return append(dst, pre[i:i+3]...)
+}
+
+func appendSuffix(dst []byte, b byte) []byte {
+
+	i := 3 * int(b)
+	return append(dst, suf[i:i+3]...)
+}
@@ -0,0 +1,68 @@
+package co
+
+import (
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendPatpPatq(t *testing.T) {
+
+	values := []uint64{0, 1, 255, 256, 65535, 65536, 1624961343, 0xffffffff, 0x100000000, 0xffffffffffffffff}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		values = append(values, uint64(r.Uint32()), r.Uint64())
+	}
+
+	for _, v := range values {
+
+		dec := strconv.FormatUint(v, 10)
+
+		p := AppendPatp([]byte("~zod "), v)
+		assert.Equal(t, "~zod ", string(p[:5]))
+
+		// Patp2Dec and buf2patq still go through the big.Int code paths.
+		back, err := Patp2Dec(string(p[5:]))
+		if assert.NoError(t, err) {
+			assert.Equal(t, dec, back)
+		}
+		assert.True(t, IsValidPatp(string(p[5:])))
+
+		buf := big.NewInt(0).SetUint64(v).Bytes()
+		if len(buf) == 0 {
+			buf = []byte{0}
+		}
+		assert.Equal(t, buf2patq(buf), string(AppendPatq(nil, v)))
+	}
+}
+
+func TestAppendPatpAllocations(t *testing.T) {
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = AppendPatp(buf[:0], 0x423e60bf60daf13f)
+		buf = AppendPatq(buf, 0x423e60bf60daf13f)
+	})
+
+	assert.Zero(t, allocs)
+	assert.Equal(t, "~dapnep-ronmyl-sampel-palnet~dapnep-ronmyl-ronler-talpur", string(buf))
+}
+
+func BenchmarkAppendPatp(b *testing.B) {
+
+	buf := make([]byte, 0, 64)
+	for i := 0; i < b.N; i++ {
+		buf = AppendPatp(buf[:0], uint64(i)<<20)
+	}
+}
+
+func BenchmarkPatp(b *testing.B) {
+
+	for i := 0; i < b.N; i++ {
+		_, _ = Patp(strconv.FormatUint(uint64(i)<<20, 10))
+	}
+}
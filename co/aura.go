@@ -0,0 +1,185 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// AuraCodec converts atoms to and from the text syntax of a single aura.
+type AuraCodec interface {
+	// Scot renders a non-negative atom as text.
+	Scot(atom *big.Int) string
+	// Slaw parses text into an atom, failing if the text is not in the
+	// canonical form produced by Scot.
+	Slaw(text string) (*big.Int, error)
+}
+
+// AuraFuncs adapts a pair of functions to the AuraCodec interface.
+type AuraFuncs struct {
+	ScotFunc func(atom *big.Int) string
+	SlawFunc func(text string) (*big.Int, error)
+}
+
+// Scot calls f.ScotFunc.
+func (f AuraFuncs) Scot(atom *big.Int) string {
+
+	return f.ScotFunc(atom)
+}
+
+// Slaw calls f.SlawFunc.
+func (f AuraFuncs) Slaw(text string) (*big.Int, error) {
+
+	return f.SlawFunc(text)
+}
+
+type auraRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]AuraCodec
+	order  []string
+}
+
+var auras = &auraRegistry{codecs: map[string]AuraCodec{}}
+
+func (r *auraRegistry) register(aura string, codec AuraCodec) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codecs[aura]; !ok {
+		r.order = append(r.order, aura)
+	}
+	r.codecs[aura] = codec
+}
+
+// lookup finds the codec for an aura the way Hoon's rend does: the most
+// specific registered aura wins, so @uxD uses @ux and @tas uses @tas rather
+// than @t.
+func (r *auraRegistry) lookup(aura string) (string, AuraCodec, bool) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name := auraName(aura)
+	for i := len(name); i > 0; i-- {
+		if codec, ok := r.codecs[name[:i]]; ok {
+			return name[:i], codec, true
+		}
+	}
+
+	return name, nil, false
+}
+
+// registered returns the registered aura names in registration order.
+func (r *auraRegistry) registered() []string {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.order))
+	_ = copy(names, r.order)
+	return names
+}
+
+// auraName normalizes an aura by dropping the leading @ and any trailing
+// uppercase size letter, so "@uxD" becomes "ux".
+func auraName(aura string) string {
+
+	name := strings.TrimPrefix(aura, "@")
+	for len(name) > 0 && name[len(name)-1] >= 'A' && name[len(name)-1] <= 'Z' {
+		name = name[:len(name)-1]
+	}
+
+	return name
+}
+
+func isValidAuraName(name string) bool {
+
+	for i := 0; i < len(name); i++ {
+		if name[i] < 'a' || name[i] > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RegisterAura adds a codec for a custom aura, or replaces the codec of an
+// existing one. The aura may be given with or without the leading @.
+func RegisterAura(aura string, codec AuraCodec) error {
+
+	name := auraName(aura)
+	if !isValidAuraName(name) {
+		return fmt.Errorf(ugi.ErrInvalidAura, aura)
+	}
+
+	if isNilCodec(codec) {
+		return fmt.Errorf(ugi.ErrNilAuraCodec, name)
+	}
+
+	auras.register(name, codec)
+	return nil
+}
+
+// isNilCodec reports whether a codec is nil, including a nil pointer or an
+// AuraFuncs missing a function, which would panic when used.
+func isNilCodec(codec AuraCodec) bool {
+
+	if f, ok := codec.(AuraFuncs); ok {
+		return f.ScotFunc == nil || f.SlawFunc == nil
+	}
+
+	v := reflect.ValueOf(codec)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// Auras returns the names of all registered auras, without the leading @.
+func Auras() []string {
+
+	return auras.registered()
+}
+
+// Scot renders an atom as text under the given aura, like Hoon's scot. As in
+// Hoon, an empty aura or one that is not registered renders as ungrouped hex,
+// e.g. 0x10000, and atoms outside the domain of an aura (e.g. a 40-bit atom as
+// @if) render as @ux. A negative atom is not an atom and renders as the empty
+// string.
+func Scot(aura string, atom *big.Int) string {
+
+	if atom == nil {
+		atom = zero
+	}
+
+	if atom.Sign() < 0 {
+		return ""
+	}
+
+	_, codec, ok := auras.lookup(aura)
+	if !ok {
+		return scotZ(atom)
+	}
+
+	return codec.Scot(atom)
+}
+
+// Slaw parses text as an atom of the given aura, like Hoon's slaw.
+func Slaw(aura string, text string) (*big.Int, error) {
+
+	name, codec, ok := auras.lookup(aura)
+	if !ok {
+		return nil, fmt.Errorf(ugi.ErrUnknownAura, name)
+	}
+
+	return codec.Slaw(text)
+}
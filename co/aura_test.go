@@ -0,0 +1,183 @@
+package co
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auraTestCase struct {
+	aura string
+	atom string
+	text string
+}
+
+var auraTestCases = []auraTestCase{
+	{aura: "p", atom: "0", text: "~zod"},
+	{aura: "p", atom: "65536", text: "~dapnep-ronmyl"},
+	{aura: "q", atom: "65536", text: "~doznec-dozzod"},
+	{aura: "ud", atom: "0", text: "0"},
+	{aura: "ud", atom: "1024", text: "1.024"},
+	{aura: "ud", atom: "1000000", text: "1.000.000"},
+	{aura: "ux", atom: "0", text: "0x0"},
+	{aura: "ux", atom: "31", text: "0x1f"},
+	{aura: "ux", atom: "65536", text: "0x1.0000"},
+	{aura: "ub", atom: "16", text: "0b1.0000"},
+	{aura: "uv", atom: "32", text: "0v10"},
+	{aura: "uw", atom: "64", text: "0w10"},
+	{aura: "uw", atom: "0", text: "0w0"},
+	{aura: "ui", atom: "1024", text: "0i1024"},
+	{aura: "sd", atom: "0", text: "--0"},
+	{aura: "sd", atom: "2000", text: "--1.000"},
+	{aura: "sd", atom: "3", text: "-2"},
+	{aura: "sx", atom: "32", text: "--0x10"},
+	{aura: "f", atom: "0", text: ".y"},
+	{aura: "f", atom: "1", text: ".n"},
+	{aura: "n", atom: "0", text: "~"},
+	{aura: "if", atom: "16909060", text: ".1.2.3.4"},
+	{aura: "is", atom: "1", text: ".0.0.0.0.0.0.0.1"},
+	{aura: "t", atom: "0", text: "~~"},
+	{aura: "t", atom: "7303014", text: "~~foo"},
+	{aura: "ta", atom: "7303014", text: "~.foo"},
+	{aura: "tas", atom: "0", text: "%$"},
+	{aura: "tas", atom: "7303014", text: "%foo"},
+	{aura: "da", atom: "170141184475152167957503069145530368000", text: "~1970.1.1"},
+	{aura: "da", atom: "170141184475152167966726441182385143808", text: "~1970.1.1..00.00.00..8000"},
+	{aura: "da", atom: "170141184475153032390377107248828645376", text: "~1970.1.1..13.01.01"},
+	{aura: "da", atom: "170141184506586659480305898381062963200", text: "~2024.1.1"},
+	{aura: "dr", atom: "0", text: "~s0"},
+	{aura: "dr", atom: "18446744073709551616", text: "~s1"},
+	{aura: "dr", atom: "1660206966633859645440", text: "~m1.s30"},
+	{aura: "dr", atom: "9223372036854775808", text: "~s0..8000"},
+}
+
+func TestScot(t *testing.T) {
+
+	for _, tt := range auraTestCases {
+		t.Run(tt.aura+"/"+tt.atom, func(t *testing.T) {
+
+			atom, _ := big.NewInt(0).SetString(tt.atom, 10)
+			assert.Equal(t, tt.text, Scot(tt.aura, atom))
+		})
+	}
+}
+
+func TestSlaw(t *testing.T) {
+
+	for _, tt := range auraTestCases {
+		t.Run(tt.aura+"/"+tt.text, func(t *testing.T) {
+
+			atom, err := Slaw(tt.aura, tt.text)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.atom, atom.String())
+			}
+		})
+	}
+}
+
+func TestSlawNonCanonical(t *testing.T) {
+
+	var testCases = []struct {
+		aura string
+		text string
+	}{
+		{aura: "ud", text: "1024"},
+		{aura: "ud", text: "01"},
+		{aura: "ud", text: "1.0"},
+		{aura: "ux", text: "0x01"},
+		{aura: "ux", text: "0x1F"},
+		{aura: "p", text: "~doznec"},
+		{aura: "sd", text: "-0"},
+		{aura: "if", text: ".01.2.3.4"},
+		{aura: "if", text: ".256.2.3.4"},
+		{aura: "t", text: "~~Foo"},
+		{aura: "tas", text: "%1foo"},
+		{aura: "da", text: "~2024.01.01"},
+		{aura: "da", text: "~2023.2.29"},
+		{aura: "da", text: "~2024.1.1..00.00.00"},
+		{aura: "dr", text: "~s60"},
+		{aura: "dr", text: "~s1..0000"},
+		{aura: "zz", text: "0x1"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.aura+"/"+tt.text, func(t *testing.T) {
+
+			_, err := Slaw(tt.aura, tt.text)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScotAuraLookup(t *testing.T) {
+
+	atom := big.NewInt(65536)
+
+	assert.Equal(t, "0x1.0000", Scot("@uxD", atom))
+	assert.Equal(t, "0x10000", Scot("@", atom))
+	assert.Equal(t, "0x10000", Scot("", atom))
+	assert.Equal(t, "0x10000", Scot("@uc", atom))
+	assert.Equal(t, "0x10000", Scot("@zz", atom))
+	assert.Equal(t, "0x0", Scot("@", big.NewInt(0)))
+	assert.Equal(t, "0x1.0000.0000", Scot("if", big.NewInt(0).Lsh(atom, 16)))
+	assert.Equal(t, "", Scot("ud", big.NewInt(-1)))
+}
+
+func TestRegisterAura(t *testing.T) {
+
+	codec := AuraFuncs{
+		ScotFunc: func(atom *big.Int) string { return "#" + atom.String() },
+		SlawFunc: func(text string) (*big.Int, error) {
+			v, _ := big.NewInt(0).SetString(text[1:], 10)
+			return v, nil
+		},
+	}
+
+	useTestAuras(t)
+	assert.NoError(t, RegisterAura("@zy", codec))
+	assert.Contains(t, Auras(), "zy")
+	assert.Equal(t, "#12", Scot("zyD", big.NewInt(12)))
+
+	atom, err := Slaw("@zy", "#12")
+	assert.NoError(t, err)
+	assert.Equal(t, "12", atom.String())
+
+	assert.Error(t, RegisterAura("@z1", codec))
+	assert.Error(t, RegisterAura("@zx", nil))
+	assert.Error(t, RegisterAura("@zx", (*nilCodec)(nil)))
+	assert.Error(t, RegisterAura("@zx", AuraFuncs{ScotFunc: codec.ScotFunc}))
+	assert.NotContains(t, Auras(), "zx")
+}
+
+func TestUseTestAuras(t *testing.T) {
+
+	t.Run("register", func(t *testing.T) {
+
+		useTestAuras(t)
+		assert.NoError(t, RegisterAura("zv", AuraFuncs{scotUd, slawUd}))
+		assert.Contains(t, Auras(), "zv")
+	})
+
+	assert.NotContains(t, Auras(), "zv")
+}
+
+type nilCodec struct{}
+
+func (*nilCodec) Scot(atom *big.Int) string { return "" }
+
+func (*nilCodec) Slaw(text string) (*big.Int, error) { return nil, nil }
+
+// useTestAuras gives a test its own copy of the aura registry, which it can
+// register auras in without affecting other tests.
+func useTestAuras(t *testing.T) {
+
+	saved := auras
+	auras = &auraRegistry{codecs: map[string]AuraCodec{}}
+	for _, name := range saved.registered() {
+		_, codec, _ := saved.lookup(name)
+		auras.register(name, codec)
+	}
+
+	t.Cleanup(func() { auras = saved })
+}
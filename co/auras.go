@@ -0,0 +1,878 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	uwAlphabet string = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-~"
+
+	// daUnixEpoch is ~1970.1.1 in seconds since the Urbit epoch.
+	daUnixEpoch uint64 = 0x8000000cce9e0d80
+	// daMaxUnix bounds the @da values rendered through package time, well
+	// within the range it can represent.
+	daMaxUnix int64 = 1 << 55
+)
+
+var (
+	u64Mask        = big.NewInt(0).Sub(big.NewInt(0).Lsh(one, 64), one)
+	u32Limit       = big.NewInt(0).Lsh(one, 32)
+	u128Limit      = big.NewInt(0).Lsh(one, 128)
+	secondsPerDay  = big.NewInt(86400)
+	daUnixEpochBig = big.NewInt(0).SetUint64(daUnixEpoch)
+)
+
+func init() {
+
+	// Registration order is the order in which auras are tried when
+	// sniffing a literal, so the more specific syntaxes come first.
+	builtins := []struct {
+		aura  string
+		codec AuraCodec
+	}{
+		{"p", AuraFuncs{scotP, slawP}},
+		{"q", AuraFuncs{scotQ, slawQ}},
+		{"da", AuraFuncs{scotDa, slawDa}},
+		{"dr", AuraFuncs{scotDr, slawDr}},
+		{"t", AuraFuncs{scotT, slawT}},
+		{"ta", AuraFuncs{scotTa, slawTa}},
+		{"tas", AuraFuncs{scotTas, slawTas}},
+		{"n", AuraFuncs{scotN, slawN}},
+		{"f", AuraFuncs{scotF, slawF}},
+		{"ud", AuraFuncs{scotUd, slawUd}},
+		{"ux", AuraFuncs{scotUx, slawUx}},
+		{"ub", AuraFuncs{scotUb, slawUb}},
+		{"uv", AuraFuncs{scotUv, slawUv}},
+		{"uw", AuraFuncs{scotUw, slawUw}},
+		{"ui", AuraFuncs{scotUi, slawUi}},
+		{"sd", AuraFuncs{scotSd, slawSd}},
+		{"sx", AuraFuncs{scotSx, slawSx}},
+		{"if", AuraFuncs{scotIf, slawIf}},
+		{"is", AuraFuncs{scotIs, slawIs}},
+	}
+
+	for _, b := range builtins {
+		auras.register(b.aura, b.codec)
+	}
+}
+
+func invalidAtom(aura, text string) error {
+
+	return fmt.Errorf(ugi.ErrInvalidAtom, aura, text)
+}
+
+// groupDigits separates digits into dot-delimited groups of size, counting
+// from the right.
+func groupDigits(digits string, size int) string {
+
+	first := len(digits) % size
+	if first == 0 {
+		first = size
+	}
+
+	var b strings.Builder
+	b.WriteString(digits[:first])
+	for i := first; i < len(digits); i += size {
+		b.WriteByte('.')
+		b.WriteString(digits[i : i+size])
+	}
+
+	return b.String()
+}
+
+// ungroupDigits reverses groupDigits, rejecting anything groupDigits would
+// not have produced, including leading zeros.
+func ungroupDigits(text string, size int, isDigit func(byte) bool) (string, bool) {
+
+	groups := strings.Split(text, ".")
+	if len(groups[0]) < 1 || len(groups[0]) > size {
+		return "", false
+	}
+
+	for _, group := range groups[1:] {
+		if len(group) != size {
+			return "", false
+		}
+	}
+
+	digits := strings.Join(groups, "")
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(digits[i]) {
+			return "", false
+		}
+	}
+
+	if len(digits) > 1 && digits[0] == '0' {
+		return "", false
+	}
+
+	return digits, true
+}
+
+func isDecDigit(c byte) bool {
+
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+
+	return isDecDigit(c) || (c >= 'a' && c <= 'f')
+}
+
+func isBinDigit(c byte) bool {
+
+	return c == '0' || c == '1'
+}
+
+func isUvDigit(c byte) bool {
+
+	return isDecDigit(c) || (c >= 'a' && c <= 'v')
+}
+
+func isUwDigit(c byte) bool {
+
+	return strings.IndexByte(uwAlphabet, c) >= 0
+}
+
+func slawGrouped(aura, prefix, text string, size, base int, isDigit func(byte) bool) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, prefix) {
+		return nil, invalidAtom(aura, text)
+	}
+
+	digits, ok := ungroupDigits(text[len(prefix):], size, isDigit)
+	if !ok {
+		return nil, invalidAtom(aura, text)
+	}
+
+	v, ok := big.NewInt(0).SetString(digits, base)
+	if !ok {
+		return nil, invalidAtom(aura, text)
+	}
+
+	return v, nil
+}
+
+func scotUd(atom *big.Int) string {
+
+	return groupDigits(atom.String(), 3)
+}
+
+func slawUd(text string) (*big.Int, error) {
+
+	return slawGrouped("ud", "", text, 3, 10, isDecDigit)
+}
+
+func scotUx(atom *big.Int) string {
+
+	return "0x" + groupDigits(atom.Text(16), 4)
+}
+
+// scotZ renders an atom like Hoon's z-co: as hex without any grouping.
+func scotZ(atom *big.Int) string {
+
+	return "0x" + atom.Text(16)
+}
+
+func slawUx(text string) (*big.Int, error) {
+
+	return slawGrouped("ux", "0x", text, 4, 16, isHexDigit)
+}
+
+func scotUb(atom *big.Int) string {
+
+	return "0b" + groupDigits(atom.Text(2), 4)
+}
+
+func slawUb(text string) (*big.Int, error) {
+
+	return slawGrouped("ub", "0b", text, 4, 2, isBinDigit)
+}
+
+func scotUv(atom *big.Int) string {
+
+	return "0v" + groupDigits(atom.Text(32), 5)
+}
+
+func slawUv(text string) (*big.Int, error) {
+
+	return slawGrouped("uv", "0v", text, 5, 32, isUvDigit)
+}
+
+func scotUw(atom *big.Int) string {
+
+	if atom.Sign() == 0 {
+		return "0w0"
+	}
+
+	var digits []byte
+	for i := atom.BitLen() - 1 - (atom.BitLen()-1)%6; i >= 0; i -= 6 {
+		d := big.NewInt(0).Rsh(atom, uint(i)).Int64() & 63
+		digits = append(digits, uwAlphabet[d])
+	}
+
+	return "0w" + groupDigits(string(digits), 5)
+}
+
+func slawUw(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "0w") {
+		return nil, invalidAtom("uw", text)
+	}
+
+	digits, ok := ungroupDigits(text[2:], 5, isUwDigit)
+	if !ok {
+		return nil, invalidAtom("uw", text)
+	}
+
+	v := big.NewInt(0)
+	for i := 0; i < len(digits); i++ {
+		v.Lsh(v, 6)
+		v.Or(v, big.NewInt(int64(strings.IndexByte(uwAlphabet, digits[i]))))
+	}
+
+	return v, nil
+}
+
+func scotUi(atom *big.Int) string {
+
+	return "0i" + atom.String()
+}
+
+func slawUi(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "0i") {
+		return nil, invalidAtom("ui", text)
+	}
+
+	digits := text[2:]
+	if digits == "" || (len(digits) > 1 && digits[0] == '0') {
+		return nil, invalidAtom("ui", text)
+	}
+
+	for i := 0; i < len(digits); i++ {
+		if !isDecDigit(digits[i]) {
+			return nil, invalidAtom("ui", text)
+		}
+	}
+
+	v, _ := big.NewInt(0).SetString(digits, 10)
+	return v, nil
+}
+
+// scotSigned renders a ZigZag-encoded signed atom: even atoms are
+// non-negative and odd atoms are negative.
+func scotSigned(atom *big.Int, unsigned func(*big.Int) string) string {
+
+	if atom.Bit(0) == 0 {
+		return "--" + unsigned(big.NewInt(0).Rsh(atom, 1))
+	}
+
+	return "-" + unsigned(big.NewInt(0).Rsh(big.NewInt(0).Add(atom, one), 1))
+}
+
+func slawSigned(aura, text string, unsigned func(string) (*big.Int, error)) (*big.Int, error) {
+
+	if strings.HasPrefix(text, "--") {
+		v, err := unsigned(text[2:])
+		if err != nil {
+			return nil, invalidAtom(aura, text)
+		}
+		return v.Lsh(v, 1), nil
+	}
+
+	if strings.HasPrefix(text, "-") {
+		v, err := unsigned(text[1:])
+		if err != nil || v.Sign() == 0 {
+			return nil, invalidAtom(aura, text)
+		}
+		return v.Sub(v.Lsh(v, 1), one), nil
+	}
+
+	return nil, invalidAtom(aura, text)
+}
+
+func scotSd(atom *big.Int) string {
+
+	return scotSigned(atom, scotUd)
+}
+
+func slawSd(text string) (*big.Int, error) {
+
+	return slawSigned("sd", text, slawUd)
+}
+
+func scotSx(atom *big.Int) string {
+
+	return scotSigned(atom, scotUx)
+}
+
+func slawSx(text string) (*big.Int, error) {
+
+	return slawSigned("sx", text, slawUx)
+}
+
+func scotP(atom *big.Int) string {
+
+	p, err := Patp(atom.String())
+	if err != nil {
+		return scotUx(atom)
+	}
+
+	return p
+}
+
+func slawP(text string) (*big.Int, error) {
+
+	if !IsValidPatp(text) {
+		return nil, fmt.Errorf(ugi.ErrInvalidP, text)
+	}
+
+	return patp2bn(text)
+}
+
+func scotQ(atom *big.Int) string {
+
+	q, err := Patq(atom.String())
+	if err != nil {
+		return scotUx(atom)
+	}
+
+	return q
+}
+
+func slawQ(text string) (*big.Int, error) {
+
+	if !IsValidPatq(text) {
+		return nil, fmt.Errorf(ugi.ErrInvalidQ, text)
+	}
+
+	return patq2bn(text)
+}
+
+func scotN(atom *big.Int) string {
+
+	if atom.Sign() != 0 {
+		return scotUx(atom)
+	}
+
+	return "~"
+}
+
+func slawN(text string) (*big.Int, error) {
+
+	if text != "~" {
+		return nil, invalidAtom("n", text)
+	}
+
+	return big.NewInt(0), nil
+}
+
+func scotF(atom *big.Int) string {
+
+	switch {
+	case atom.Cmp(zero) == 0:
+		return ".y"
+	case atom.Cmp(one) == 0:
+		return ".n"
+	}
+
+	return scotUx(atom)
+}
+
+func slawF(text string) (*big.Int, error) {
+
+	switch text {
+	case ".y":
+		return big.NewInt(0), nil
+	case ".n":
+		return big.NewInt(1), nil
+	}
+
+	return nil, invalidAtom("f", text)
+}
+
+func scotIf(atom *big.Int) string {
+
+	if atom.Cmp(u32Limit) >= 0 {
+		return scotUx(atom)
+	}
+
+	v := atom.Uint64()
+	return fmt.Sprintf(".%d.%d.%d.%d", v>>24, (v>>16)&0xff, (v>>8)&0xff, v&0xff)
+}
+
+func slawIf(text string) (*big.Int, error) {
+
+	parts := strings.Split(text, ".")
+	if len(parts) != 5 || parts[0] != "" {
+		return nil, invalidAtom("if", text)
+	}
+
+	var v uint64
+	for _, part := range parts[1:] {
+		octet, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return nil, invalidAtom("if", text)
+		}
+		v = v<<8 | octet
+	}
+
+	atom := big.NewInt(0).SetUint64(v)
+	if scotIf(atom) != text {
+		return nil, invalidAtom("if", text)
+	}
+
+	return atom, nil
+}
+
+func scotIs(atom *big.Int) string {
+
+	if atom.Cmp(u128Limit) >= 0 {
+		return scotUx(atom)
+	}
+
+	var b strings.Builder
+	for i := 7; i >= 0; i-- {
+		group := big.NewInt(0).Rsh(atom, uint(16*i)).Uint64() & 0xffff
+		b.WriteByte('.')
+		b.WriteString(strconv.FormatUint(group, 16))
+	}
+
+	return b.String()
+}
+
+func slawIs(text string) (*big.Int, error) {
+
+	parts := strings.Split(text, ".")
+	if len(parts) != 9 || parts[0] != "" {
+		return nil, invalidAtom("is", text)
+	}
+
+	v := big.NewInt(0)
+	for _, part := range parts[1:] {
+		group, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return nil, invalidAtom("is", text)
+		}
+		v.Lsh(v, 16)
+		v.Or(v, big.NewInt(0).SetUint64(group))
+	}
+
+	if scotIs(v) != text {
+		return nil, invalidAtom("is", text)
+	}
+
+	return v, nil
+}
+
+// cord returns the bytes of an atom in little-endian order, which is how
+// Hoon stores text in atoms.
+func cord(atom *big.Int) []byte {
+
+	buf := atom.Bytes()
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	return buf
+}
+
+// uncord is the inverse of cord.
+func uncord(text []byte) *big.Int {
+
+	buf := make([]byte, len(text))
+	for i, c := range text {
+		buf[len(text)-1-i] = c
+	}
+
+	return big.NewInt(0).SetBytes(buf)
+}
+
+func isKnotChar(c byte) bool {
+
+	return (c >= 'a' && c <= 'z') || isDecDigit(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isTerm(text []byte) bool {
+
+	for i, c := range text {
+		if (c >= 'a' && c <= 'z') || (i > 0 && (isDecDigit(c) || c == '-')) {
+			continue
+		}
+		return false
+	}
+
+	return len(text) > 0
+}
+
+func scotT(atom *big.Int) string {
+
+	text := cord(atom)
+	if !utf8.Valid(text) {
+		return scotUx(atom)
+	}
+
+	var b strings.Builder
+	b.WriteString("~~")
+	for _, r := range string(text) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('.')
+		case r == '.':
+			b.WriteString("~.")
+		case r == '~':
+			b.WriteString("~~")
+		default:
+			b.WriteByte('~')
+			b.WriteString(strconv.FormatInt(int64(r), 16))
+			b.WriteByte('.')
+		}
+	}
+
+	return b.String()
+}
+
+func slawT(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "~~") {
+		return nil, invalidAtom("t", text)
+	}
+
+	var buf []byte
+	for i := 2; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case (c >= 'a' && c <= 'z') || isDecDigit(c) || c == '-':
+			buf = append(buf, c)
+		case c == '.':
+			buf = append(buf, ' ')
+		case c == '~' && i+1 < len(text) && (text[i+1] == '.' || text[i+1] == '~'):
+			i++
+			buf = append(buf, text[i])
+		case c == '~':
+			end := strings.IndexByte(text[i:], '.')
+			if end < 2 {
+				return nil, invalidAtom("t", text)
+			}
+			r, err := strconv.ParseUint(text[i+1:i+end], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return nil, invalidAtom("t", text)
+			}
+			buf = append(buf, string(rune(r))...)
+			i += end
+		default:
+			return nil, invalidAtom("t", text)
+		}
+	}
+
+	atom := uncord(buf)
+	if scotT(atom) != text {
+		return nil, invalidAtom("t", text)
+	}
+
+	return atom, nil
+}
+
+func scotTa(atom *big.Int) string {
+
+	text := cord(atom)
+	for _, c := range text {
+		if !isKnotChar(c) {
+			return scotUx(atom)
+		}
+	}
+
+	return "~." + string(text)
+}
+
+func slawTa(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "~.") {
+		return nil, invalidAtom("ta", text)
+	}
+
+	for i := 2; i < len(text); i++ {
+		if !isKnotChar(text[i]) {
+			return nil, invalidAtom("ta", text)
+		}
+	}
+
+	return uncord([]byte(text[2:])), nil
+}
+
+func scotTas(atom *big.Int) string {
+
+	if atom.Sign() == 0 {
+		return "%$"
+	}
+
+	text := cord(atom)
+	if !isTerm(text) {
+		return scotUx(atom)
+	}
+
+	return "%" + string(text)
+}
+
+func slawTas(text string) (*big.Int, error) {
+
+	if text == "%$" {
+		return big.NewInt(0), nil
+	}
+
+	if !strings.HasPrefix(text, "%") || !isTerm([]byte(text[1:])) {
+		return nil, invalidAtom("tas", text)
+	}
+
+	return uncord([]byte(text[1:])), nil
+}
+
+// fractionWords renders the fractional second of a @da or @dr as dotted
+// 16-bit hex words, most significant first, with trailing zero words
+// dropped.
+func fractionWords(frac uint64) string {
+
+	var words []string
+	for i := 3; i >= 0; i-- {
+		words = append(words, fmt.Sprintf("%04x", (frac>>(16*uint(i)))&0xffff))
+	}
+
+	for len(words) > 0 && words[len(words)-1] == "0000" {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, ".")
+}
+
+func parseFractionWords(text string) (uint64, bool) {
+
+	words := strings.Split(text, ".")
+	if len(words) > 4 {
+		return 0, false
+	}
+
+	var frac uint64
+	for i, word := range words {
+		if len(word) != 4 {
+			return 0, false
+		}
+		w, err := strconv.ParseUint(word, 16, 16)
+		if err != nil {
+			return 0, false
+		}
+		frac |= w << (16 * uint(3-i))
+	}
+
+	return frac, true
+}
+
+func splitSeconds(atom *big.Int) (*big.Int, uint64) {
+
+	return big.NewInt(0).Rsh(atom, 64), big.NewInt(0).And(atom, u64Mask).Uint64()
+}
+
+func joinSeconds(sec *big.Int, frac uint64) *big.Int {
+
+	v := big.NewInt(0).Lsh(sec, 64)
+	return v.Or(v, big.NewInt(0).SetUint64(frac))
+}
+
+func scotDa(atom *big.Int) string {
+
+	sec, frac := splitSeconds(atom)
+	unix := sec.Sub(sec, daUnixEpochBig)
+	if !unix.IsInt64() || unix.Int64() > daMaxUnix || unix.Int64() < -daMaxUnix {
+		return scotUx(atom)
+	}
+
+	t := time.Unix(unix.Int64(), 0).UTC()
+
+	year := strconv.Itoa(t.Year())
+	if t.Year() <= 0 {
+		year = strconv.Itoa(1-t.Year()) + "-"
+	}
+
+	da := fmt.Sprintf("~%s.%d.%d", year, t.Month(), t.Day())
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && frac == 0 {
+		return da
+	}
+
+	da += fmt.Sprintf("..%02d.%02d.%02d", t.Hour(), t.Minute(), t.Second())
+	if frac != 0 {
+		da += ".." + fractionWords(frac)
+	}
+
+	return da
+}
+
+func slawDa(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "~") {
+		return nil, invalidAtom("da", text)
+	}
+
+	parts := strings.Split(text[1:], "..")
+	if len(parts) > 3 {
+		return nil, invalidAtom("da", text)
+	}
+
+	date := strings.Split(parts[0], ".")
+	if len(date) != 3 {
+		return nil, invalidAtom("da", text)
+	}
+
+	bc := strings.HasSuffix(date[0], "-")
+	ymd := make([]int, 3)
+	for i, field := range []string{strings.TrimSuffix(date[0], "-"), date[1], date[2]} {
+		n, ok := atoi(field)
+		if !ok {
+			return nil, invalidAtom("da", text)
+		}
+		ymd[i] = n
+	}
+
+	if bc {
+		ymd[0] = 1 - ymd[0]
+	}
+
+	hms := make([]int, 3)
+	if len(parts) > 1 {
+		clock := strings.Split(parts[1], ".")
+		if len(clock) != 3 {
+			return nil, invalidAtom("da", text)
+		}
+		for i, field := range clock {
+			n, ok := atoi(field)
+			if !ok {
+				return nil, invalidAtom("da", text)
+			}
+			hms[i] = n
+		}
+	}
+
+	var frac uint64
+	if len(parts) > 2 {
+		var ok bool
+		if frac, ok = parseFractionWords(parts[2]); !ok {
+			return nil, invalidAtom("da", text)
+		}
+	}
+
+	t := time.Date(ymd[0], time.Month(ymd[1]), ymd[2], hms[0], hms[1], hms[2], 0, time.UTC)
+	sec := big.NewInt(0).Add(big.NewInt(t.Unix()), daUnixEpochBig)
+
+	atom := joinSeconds(sec, frac)
+	if scotDa(atom) != text {
+		return nil, invalidAtom("da", text)
+	}
+
+	return atom, nil
+}
+
+func scotDr(atom *big.Int) string {
+
+	sec, frac := splitSeconds(atom)
+	days, rem := big.NewInt(0).QuoRem(sec, secondsPerDay, big.NewInt(0))
+	r := rem.Int64()
+
+	var fields []string
+	if days.Sign() != 0 {
+		fields = append(fields, "d"+days.String())
+	}
+	for _, f := range []struct {
+		unit string
+		n    int64
+	}{{"h", r / 3600}, {"m", r / 60 % 60}, {"s", r % 60}} {
+		if f.n != 0 {
+			fields = append(fields, f.unit+strconv.FormatInt(f.n, 10))
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = []string{"s0"}
+	}
+
+	dr := "~" + strings.Join(fields, ".")
+	if frac != 0 {
+		dr += ".." + fractionWords(frac)
+	}
+
+	return dr
+}
+
+func slawDr(text string) (*big.Int, error) {
+
+	if !strings.HasPrefix(text, "~") {
+		return nil, invalidAtom("dr", text)
+	}
+
+	parts := strings.Split(text[1:], "..")
+	if len(parts) > 2 {
+		return nil, invalidAtom("dr", text)
+	}
+
+	units := map[byte]*big.Int{
+		'd': secondsPerDay,
+		'h': big.NewInt(3600),
+		'm': big.NewInt(60),
+		's': one,
+	}
+
+	sec := big.NewInt(0)
+	for _, field := range strings.Split(parts[0], ".") {
+		if len(field) < 2 {
+			return nil, invalidAtom("dr", text)
+		}
+		unit, ok := units[field[0]]
+		if !ok {
+			return nil, invalidAtom("dr", text)
+		}
+		n, ok := big.NewInt(0).SetString(field[1:], 10)
+		if !ok || n.Sign() < 0 {
+			return nil, invalidAtom("dr", text)
+		}
+		sec.Add(sec, n.Mul(n, unit))
+	}
+
+	var frac uint64
+	if len(parts) > 1 {
+		var ok bool
+		if frac, ok = parseFractionWords(parts[1]); !ok {
+			return nil, invalidAtom("dr", text)
+		}
+	}
+
+	atom := joinSeconds(sec, frac)
+	if scotDr(atom) != text {
+		return nil, invalidAtom("dr", text)
+	}
+
+	return atom, nil
+}
+
+// atoi parses a non-empty string of decimal digits.
+func atoi(s string) (int, bool) {
+
+	if s == "" || len(s) > 9 {
+		return 0, false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !isDecDigit(s[i]) {
+			return 0, false
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
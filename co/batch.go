@@ -0,0 +1,234 @@
+package co
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// batchChunk is the number of consecutive items a worker takes from a slice at
+// a time, so that large batches don't spend their time on channel operations.
+const batchChunk int = 256
+
+// BatchOptions configures Batch and Stream. The zero value is ready to use.
+type BatchOptions struct {
+	// Workers is the number of goroutines converting items. Zero means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+func (o BatchOptions) workers() int {
+
+	if o.Workers > 0 {
+		return o.Workers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// Result is the outcome of converting one item of a batch.
+type Result struct {
+	// Index is the position of the item in the batch.
+	Index int
+	In    string
+	Out   string
+	Err   error
+}
+
+// ConvertFunc is any of the conversions of this package, or the methods of
+// a Converter, that take one string and return another.
+type ConvertFunc func(string) (string, error)
+
+/*
+Batch applies convert to every item of args across a pool of workers, and
+returns the results in the same order as args.
+
+A failed item doesn't fail the batch; its error is kept in its Result. If ctx
+is canceled before every item is converted, the items that weren't get
+ctx.Err() as their error and it is returned as well.
+*/
+func Batch(ctx context.Context, convert ConvertFunc, args []string, opts BatchOptions) ([]Result, error) {
+
+	results := make([]Result, len(args))
+	chunks := make(chan int)
+	// skipped is set by a worker that leaves an item unconverted.
+	var skipped int32
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + batchChunk
+				if end > len(args) {
+					end = len(args)
+				}
+				for i := start; i < end; i++ {
+					results[i] = convertItem(ctx, convert, i, args[i])
+					if results[i].Err != nil && results[i].Err == ctx.Err() {
+						atomic.StoreInt32(&skipped, 1)
+					}
+				}
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(args); next += batchChunk {
+		select {
+		case chunks <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if next >= len(args) && atomic.LoadInt32(&skipped) == 0 {
+		return results, nil
+	}
+
+	for i := next; i < len(args); i++ {
+		results[i] = Result{Index: i, In: args[i], Err: ctx.Err()}
+	}
+
+	return results, ctx.Err()
+}
+
+func convertItem(ctx context.Context, convert ConvertFunc, i int, arg string) Result {
+
+	if err := ctx.Err(); err != nil {
+		return Result{Index: i, In: arg, Err: err}
+	}
+
+	out, err := convert(arg)
+	return Result{Index: i, In: arg, Out: out, Err: err}
+}
+
+// PatpBatch converts a batch of numbers to @p-encoded strings with Batch.
+func PatpBatch(ctx context.Context, args []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Patp, args, opts)
+}
+
+// Patp2DecBatch converts a batch of @p-encoded strings to decimal-encoded
+// strings with Batch.
+func Patp2DecBatch(ctx context.Context, names []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Patp2Dec, names, opts)
+}
+
+// PatqBatch converts a batch of numbers to @q-encoded strings with Batch.
+func PatqBatch(ctx context.Context, args []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Patq, args, opts)
+}
+
+// Patq2DecBatch converts a batch of @q-encoded strings to decimal-encoded
+// strings with Batch.
+func Patq2DecBatch(ctx context.Context, names []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Patq2Dec, names, opts)
+}
+
+// ClanBatch determines the ship class of a batch of @p values with Batch.
+func ClanBatch(ctx context.Context, names []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Clan, names, opts)
+}
+
+// SeinBatch determines the parent of a batch of @p values with Batch.
+func SeinBatch(ctx context.Context, names []string, opts BatchOptions) ([]Result, error) {
+
+	return Batch(ctx, Sein, names, opts)
+}
+
+type streamJob struct {
+	index int
+	arg   string
+	done  chan Result
+}
+
+/*
+Stream is Batch for a channel of items: it applies convert to everything
+received from in across a pool of workers, and sends the results, in the
+order the items were received, on the channel it returns. That channel is
+closed once in is closed and every result has been sent.
+
+If ctx is canceled, Stream stops receiving from in and closes the results
+channel without sending the rest of the results. Results sent just before
+the cancellation, at most one per worker and one more, may still be waiting
+in the channel.
+*/
+func Stream(ctx context.Context, convert ConvertFunc, in <-chan string, opts BatchOptions) <-chan Result {
+
+	workers := opts.workers()
+	out := make(chan Result, workers)
+	jobs := make(chan streamJob, workers)
+	// pending holds the jobs in the order they were received, so that their
+	// results can be sent in that order however quickly each one finishes.
+	pending := make(chan chan Result, 4*workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for job := range jobs {
+				job.done <- convertItem(ctx, convert, job.index, job.arg)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+
+		for index := 0; ; index++ {
+
+			var (
+				arg string
+				ok  bool
+			)
+			select {
+			case arg, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			job := streamJob{index: index, arg: arg, done: make(chan Result, 1)}
+			select {
+			case pending <- job.done:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				job.done <- Result{Index: index, In: arg, Err: ctx.Err()}
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+
+		for done := range pending {
+			result := <-done
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
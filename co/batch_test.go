@@ -0,0 +1,159 @@
+package co
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+
+	args := make([]string, 2000)
+	for i := range args {
+		args[i] = strconv.Itoa(i * 1000)
+	}
+	args[1234] = "not a number"
+
+	results, err := PatpBatch(context.Background(), args, BatchOptions{Workers: 4})
+	assert.NoError(t, err)
+	if !assert.Len(t, results, len(args)) {
+		return
+	}
+
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+		assert.Equal(t, args[i], r.In)
+		expected, expectedErr := Patp(args[i])
+		assert.Equal(t, expected, r.Out)
+		assert.Equal(t, expectedErr, r.Err)
+	}
+	assert.EqualError(t, results[1234].Err, "invalid integer string: not a number")
+
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Out
+	}
+	decs, err := Patp2DecBatch(context.Background(), names, BatchOptions{})
+	assert.NoError(t, err)
+	for i, r := range decs {
+		if i != 1234 {
+			assert.Equal(t, args[i], r.Out)
+		}
+	}
+}
+
+func TestBatchCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := ClanBatch(ctx, []string{"~zod", "~marzod"}, BatchOptions{})
+	assert.Equal(t, context.Canceled, err)
+	for _, r := range results {
+		assert.Equal(t, context.Canceled, r.Err)
+		assert.Empty(t, r.Out)
+	}
+}
+
+func TestBatchCanceledAfterConverting(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Canceling while converting the last item leaves nothing unconverted.
+	args := []string{"0", "1", "2"}
+	results, err := Batch(ctx, func(arg string) (string, error) {
+		if arg == "2" {
+			cancel()
+		}
+		return Patp(arg)
+	}, args, BatchOptions{Workers: 1})
+	assert.NoError(t, err)
+	for _, r := range results {
+		assert.NoError(t, r.Err)
+	}
+
+	// Canceling while converting the first item leaves the rest.
+	ctx, cancel = context.WithCancel(context.Background())
+	results, err = Batch(ctx, func(arg string) (string, error) {
+		cancel()
+		return Patp(arg)
+	}, args, BatchOptions{Workers: 1})
+	assert.Equal(t, context.Canceled, err)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, context.Canceled, results[2].Err)
+}
+
+func TestStream(t *testing.T) {
+
+	in := make(chan string)
+	go func() {
+		for i := 0; i < 1000; i++ {
+			in <- strconv.Itoa(i)
+		}
+		in <- "~zod"
+		close(in)
+	}()
+
+	index := 0
+	for r := range Stream(context.Background(), Patq, in, BatchOptions{Workers: 3}) {
+		assert.Equal(t, index, r.Index)
+		if index < 1000 {
+			expected, _ := Patq(strconv.Itoa(index))
+			assert.NoError(t, r.Err)
+			assert.Equal(t, expected, r.Out)
+		} else {
+			assert.EqualError(t, r.Err, "invalid integer string: ~zod")
+		}
+		index++
+	}
+	assert.Equal(t, 1001, index)
+}
+
+func TestStreamCanceled(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The input is never closed, so the results channel is only closed
+	// because of the cancellation.
+	in := make(chan string)
+	results := Stream(ctx, Patp, in, BatchOptions{})
+
+	in <- "0"
+	r := <-results
+	assert.Equal(t, "~zod", r.Out)
+
+	cancel()
+	for range results {
+	}
+}
+
+func TestStreamCanceledStopsSending(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for i := 0; i < 10000; i++ {
+			select {
+			case in <- strconv.Itoa(i):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := Stream(ctx, Patp, in, BatchOptions{Workers: 2})
+	<-results
+	cancel()
+
+	late := 0
+	for range results {
+		late++
+	}
+	assert.LessOrEqual(t, late, 3)
+}
@@ -0,0 +1,191 @@
+package co
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheSize is the number of results a Cache holds if NewCache is
+// given a size that isn't positive.
+const DefaultCacheSize int = 4096
+
+// Converter converts between numbers and @p names. The package-level functions
+// are available as Pure, and a Cache memoizes another Converter.
+type Converter interface {
+	Patp(arg string) (string, error)
+	Patp2Dec(name string) (string, error)
+	Clan(who string) (string, error)
+	Sein(name string) (string, error)
+}
+
+type pure struct{}
+
+func (pure) Patp(arg string) (string, error)      { return Patp(arg) }
+func (pure) Patp2Dec(name string) (string, error) { return Patp2Dec(name) }
+func (pure) Clan(who string) (string, error)      { return Clan(who) }
+func (pure) Sein(name string) (string, error)     { return Sein(name) }
+
+// Pure is the Converter that calls the package-level functions, which keep
+// no state.
+var Pure Converter = pure{}
+
+type cacheOp uint8
+
+const (
+	opPatp cacheOp = iota
+	opPatp2Dec
+	opClan
+	opSein
+)
+
+type cacheKey struct {
+	op  cacheOp
+	arg string
+}
+
+type cacheEntry struct {
+	key    cacheKey
+	result string
+}
+
+// CacheStats is a snapshot of how a Cache has been used.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Len is the number of results held, out of at most Size.
+	Len  int
+	Size int
+}
+
+/*
+Cache is a Converter that remembers the most recently used results of another
+Converter, up to a fixed number of them, evicting the least recently used
+first. Errors are not remembered.
+
+A Cache is safe for concurrent use. Each Cache is independent, so callers can
+keep one per server, per request or per anything else.
+*/
+type Cache struct {
+	next Converter
+
+	mu        sync.Mutex
+	size      int
+	order     *list.List
+	entries   map[cacheKey]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// NewCache returns a Cache of the given size around the package-level
+// functions.
+func NewCache(size int) *Cache {
+
+	return NewCacheFor(Pure, size)
+}
+
+// NewCacheFor returns a Cache of the given size around another Converter.
+func NewCacheFor(next Converter, size int) *Cache {
+
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &Cache{
+		next:    next,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element, size),
+	}
+}
+
+// Patp converts a number to a @p-encoded string.
+func (c *Cache) Patp(arg string) (string, error) {
+
+	return c.get(cacheKey{opPatp, arg}, c.next.Patp)
+}
+
+// Patp2Dec converts a @p-encoded string to a decimal-encoded string.
+func (c *Cache) Patp2Dec(name string) (string, error) {
+
+	return c.get(cacheKey{opPatp2Dec, name}, c.next.Patp2Dec)
+}
+
+// Clan determines the ship class of a @p value.
+func (c *Cache) Clan(who string) (string, error) {
+
+	return c.get(cacheKey{opClan, who}, c.next.Clan)
+}
+
+// Sein determines the parent of a @p value.
+func (c *Cache) Sein(name string) (string, error) {
+
+	return c.get(cacheKey{opSein, name}, c.next.Sein)
+}
+
+// Stats returns the current statistics of the cache.
+func (c *Cache) Stats() CacheStats {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Len:       c.order.Len(),
+		Size:      c.size,
+	}
+}
+
+// Purge removes every result from the cache and resets its statistics.
+func (c *Cache) Purge() {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[cacheKey]*list.Element, c.size)
+	c.hits, c.misses, c.evictions = 0, 0, 0
+}
+
+func (c *Cache) get(key cacheKey, convert func(string) (string, error)) (string, error) {
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		c.hits++
+		result := elem.Value.(*cacheEntry).result
+		c.mu.Unlock()
+		return result, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Convert without holding the lock, so that a slow conversion doesn't
+	// hold up hits. Two goroutines may both miss on the same key, which only
+	// costs a repeated conversion.
+	result, err := convert(key.arg)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return result, nil
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.evictions++
+	}
+
+	return result, nil
+}
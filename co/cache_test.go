@@ -0,0 +1,112 @@
+package co
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingConverter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingConverter) count(name string) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[name]++
+}
+
+func (c *countingConverter) Patp(arg string) (string, error) {
+
+	c.count("patp " + arg)
+	return Patp(arg)
+}
+
+func (c *countingConverter) Patp2Dec(name string) (string, error) {
+
+	c.count("patp2dec " + name)
+	return Patp2Dec(name)
+}
+
+func (c *countingConverter) Clan(who string) (string, error) {
+
+	c.count("clan " + who)
+	return Clan(who)
+}
+
+func (c *countingConverter) Sein(name string) (string, error) {
+
+	c.count("sein " + name)
+	return Sein(name)
+}
+
+func TestCache(t *testing.T) {
+
+	counter := &countingConverter{calls: map[string]int{}}
+	cache := NewCacheFor(counter, 2)
+
+	for i := 0; i < 3; i++ {
+		p, err := cache.Patp("1624961343")
+		assert.NoError(t, err)
+		assert.Equal(t, "~sampel-palnet", p)
+	}
+	assert.Equal(t, 1, counter.calls["patp 1624961343"])
+
+	// The same argument to a different conversion is a different result.
+	dec, err := cache.Patp2Dec("~sampel-palnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "1624961343", dec)
+
+	clan, err := cache.Clan("~sampel-palnet")
+	assert.NoError(t, err)
+	assert.Equal(t, ShipClassPlanet, clan)
+
+	// That evicted Patp, the least recently used.
+	_, err = cache.Patp("1624961343")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, counter.calls["patp 1624961343"])
+
+	// Errors aren't remembered.
+	for i := 0; i < 2; i++ {
+		_, err = cache.Sein("~sampel-palnez")
+		assert.EqualError(t, err, "invalid @p: ~sampel-palnez")
+	}
+	assert.Equal(t, 2, counter.calls["sein ~sampel-palnez"])
+
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 6, Evictions: 2, Len: 2, Size: 2}, cache.Stats())
+
+	cache.Purge()
+	assert.Equal(t, CacheStats{Size: 2}, cache.Stats())
+}
+
+func TestCacheConcurrent(t *testing.T) {
+
+	cache := NewCache(64)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				arg := strconv.Itoa((i*7 + g) % 100)
+				p, err := cache.Patp(arg)
+				if !assert.NoError(t, err) {
+					return
+				}
+				expected, _ := Patp(arg)
+				assert.Equal(t, expected, p)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(8000), stats.Hits+stats.Misses)
+	assert.Equal(t, 64, stats.Len)
+	assert.Equal(t, DefaultCacheSize, NewCache(0).Stats().Size)
+}
@@ -0,0 +1,95 @@
+package co
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// slowClan and slowSein are the original implementations, which unscramble
+// the name before looking at it.
+func slowClan(t *testing.T, name string) string {
+
+	v, err := patp2bn(name)
+	assert.NoError(t, err)
+
+	wid := met(three, v, nil).Int64()
+	switch {
+	case wid <= 1:
+		return ShipClassGalaxy
+	case wid <= 2:
+		return ShipClassStar
+	case wid <= 4:
+		return ShipClassPlanet
+	case wid <= 8:
+		return ShipClassMoon
+	}
+
+	return ShipClassComet
+}
+
+func slowSein(t *testing.T, name string) string {
+
+	v, err := patp2bn(name)
+	assert.NoError(t, err)
+
+	var res *big.Int
+	switch slowClan(t, name) {
+	case ShipClassGalaxy:
+		res = v
+	case ShipClassStar:
+		res = end(three, one, v)
+	case ShipClassPlanet:
+		res = end(four, one, v)
+	case ShipClassMoon:
+		res = end(five, one, v)
+	default:
+		res = zero
+	}
+
+	p, err := Patp(res.String())
+	assert.NoError(t, err)
+	return p
+}
+
+func TestClanFromStructure(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	var values []*big.Int
+	for bits := 1; bits <= 160; bits++ {
+		for i := 0; i < 20; i++ {
+			values = append(values, big.NewInt(0).Rand(r, big.NewInt(0).Lsh(one, uint(bits))))
+		}
+	}
+
+	for _, v := range values {
+
+		name, err := Patp(v.String())
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		clan, err := Clan(name)
+		assert.NoError(t, err)
+		assert.Equal(t, slowClan(t, name), clan, name)
+
+		sein, err := Sein(name)
+		assert.NoError(t, err)
+		assert.Equal(t, slowSein(t, name), sein, name)
+	}
+}
+
+func TestClanNonCanonical(t *testing.T) {
+
+	// Names that aren't canonical are still classified by their value.
+	clan, err := Clan("~doznec")
+	assert.NoError(t, err)
+	assert.Equal(t, ShipClassGalaxy, clan)
+
+	sein, err := Sein("~doznec")
+	assert.NoError(t, err)
+	assert.Equal(t, "~nec", sein)
+}
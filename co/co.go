@@ -4,7 +4,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -13,8 +12,7 @@ import (
 )
 
 const (
-	namePartitionPattern string = ".{1,3}"
-	pre                  string = "dozmarbinwansamlitsighidfidlissogdirwacsabwissib" +
+	pre string = "dozmarbinwansamlitsighidfidlissogdirwacsabwissib" +
 		"rigsoldopmodfoglidhopdardorlorhodfolrintogsilmir" +
 		"holpaslacrovlivdalsatlibtabhanticpidtorbolfosdot" +
 		"losdilforpilramtirwintadbicdifrocwidbisdasmidlop" +
@@ -68,15 +66,13 @@ var (
 
 	// Prefixes is the slice of three letter strings that can be used as the first
 	// of two syllables in a syllable pair that makes up a ship name.
-	Prefixes = regexp.MustCompile(namePartitionPattern).FindAllString(pre, -1)
+	Prefixes = partition(pre)
 	// Suffixes is the slice of three letter strings that can be used as the second
 	// of two syllables, or one of one syllables in the case of galaxies, that make up a ship name.
-	Suffixes = regexp.MustCompile(namePartitionPattern).FindAllString(suf, -1)
+	Suffixes = partition(suf)
 
-	prefixesIndex = map[string]int{}
-	suffixesIndex = map[string]int{}
-	prefixes      = make([]string, len(Prefixes))
-	suffixes      = make([]string, len(Suffixes))
+	prefixes = make([]string, len(Prefixes))
+	suffixes = make([]string, len(Suffixes))
 )
 
 func init() {
@@ -88,17 +84,37 @@ func init() {
 
 	// This assumes length of prefixes and suffixes are the same, which they should be.
 	for i := 0; i < len(Prefixes); i++ {
-		prefixesIndex[Prefixes[i]] = i
-		suffixesIndex[Suffixes[i]] = i
+		prefixLookup[sylKey(Prefixes[i])] = uint16(i) + 1
+		suffixLookup[sylKey(Suffixes[i])] = uint16(i) + 1
 	}
 }
 
+// partition splits a string into three character syllables; the last one
+// may be shorter.
+func partition(str string) []string {
+
+	syls := make([]string, 0, (len(str)+2)/3)
+	for i := 0; i < len(str); i += 3 {
+		end := i + 3
+		if end > len(str) {
+			end = len(str)
+		}
+		syls = append(syls, str[i:end])
+	}
+
+	return syls
+}
+
 func patp2syls(name string) []string {
 
-	removeCharsPattern := regexp.MustCompile(`[\^~-]`)
-	normalizedName := removeCharsPattern.ReplaceAllString(name, "")
-	partitionPattern := regexp.MustCompile(namePartitionPattern)
-	return partitionPattern.FindAllString(normalizedName, -1)
+	normalizedName := strings.Map(func(r rune) rune {
+		if r == '^' || r == '~' || r == '-' {
+			return -1
+		}
+		return r
+	}, name)
+
+	return partition(normalizedName)
 }
 
 func bex(n *big.Int) *big.Int {
@@ -153,9 +169,9 @@ func Patp2Hex(name string) (string, error) {
 	hasLengthOne := len(syls) == 1
 	for i := 0; i < len(syls); i++ {
 		if i%2 != 0 || hasLengthOne {
-			addr += syl2bin(suffixesIndex[syls[i]])
+			addr += syl2bin(suffixIndex(syls[i]))
 		} else {
-			addr += syl2bin(prefixesIndex[syls[i]])
+			addr += syl2bin(prefixIndex(syls[i]))
 		}
 	}
 
@@ -202,6 +218,10 @@ func patp2bn(name string) (*big.Int, error) {
 // Patp2Dec converts a @p-encoded string to a decimal-encoded string.
 func Patp2Dec(name string) (string, error) {
 
+	if v, err := ParsePatp(name); err == nil {
+		return strconv.FormatUint(v, 10), nil
+	}
+
 	dec, err := patp2bn(name)
 	if err != nil {
 		return "", err
@@ -218,6 +238,10 @@ func Patq(arg string) (string, error) {
 		return "", fmt.Errorf(ugi.ErrInvalidInt, arg)
 	}
 
+	if v.IsUint64() {
+		return string(AppendPatq(nil, v.Uint64())), nil
+	}
+
 	buf := v.Bytes()
 	// This is needed for a value of zero
 	if len(buf) == 0 {
@@ -348,9 +372,9 @@ func splat(chunks []string) string {
 			syls = []string{chunk[:3], chunk[3:]}
 		}
 		if len(syls) == 1 {
-			hexStr += dec2hex(suffixesIndex[syls[0]])
+			hexStr += dec2hex(suffixIndex(syls[0]))
 		} else {
-			hexStr += dec2hex(prefixesIndex[syls[0]]) + dec2hex(suffixesIndex[syls[1]])
+			hexStr += dec2hex(prefixIndex(syls[0])) + dec2hex(suffixIndex(syls[1]))
 		}
 	}
 
@@ -375,6 +399,10 @@ func patq2bn(name string) (*big.Int, error) {
 // Patq2Dec converts a @q-encoded string to a decimal-encoded string.
 func Patq2Dec(name string) (string, error) {
 
+	if v, err := ParsePatq(name); err == nil {
+		return strconv.FormatUint(v, 10), nil
+	}
+
 	v, err := patq2bn(name)
 	if err != nil {
 		return "", err
@@ -386,6 +414,13 @@ func Patq2Dec(name string) (string, error) {
 // Clan determines the ship class of a @p value.
 func Clan(who string) (string, error) {
 
+	// The class only depends on how many bytes the name spells, which for a
+	// canonical name is given by its syllables alone, so there's no need to
+	// unscramble it.
+	if n, _, _, ok := scanName(who, false, nil); ok {
+		return clanOfBytes(n), nil
+	}
+
 	name, err := patp2bn(who)
 	if err != nil {
 		return ShipClassEmpty, err
@@ -409,9 +444,38 @@ func Clan(who string) (string, error) {
 	return ShipClassComet, nil
 }
 
+func clanOfBytes(n int) string {
+
+	switch {
+	case n <= 1:
+		return ShipClassGalaxy
+	case n <= 2:
+		return ShipClassStar
+	case n <= 4:
+		return ShipClassPlanet
+	case n <= 8:
+		return ShipClassMoon
+	}
+
+	return ShipClassComet
+}
+
 // Sein determines the parent of a @p value.
 func Sein(name string) (string, error) {
 
+	if n, v, fits, ok := scanName(name, false, nil); ok && fits {
+		who := ob.Fynd64(v)
+		switch clanOfBytes(n) {
+		case ShipClassStar:
+			who &= 0xff
+		case ShipClassPlanet:
+			who &= 0xffff
+		case ShipClassMoon:
+			who &= 0xffffffff
+		}
+		return string(AppendPatp(nil, who)), nil
+	}
+
 	who, err := patp2bn(name)
 	if err != nil {
 		return "", err
@@ -459,10 +523,10 @@ func IsValidPat(name string) bool {
 	sylsLen := len(syls)
 	for i, syl := range syls {
 		if i%2 != 0 || sylsLen == 1 {
-			if _, ok := suffixesIndex[syl]; !ok {
+			if _, ok := suffixByte(syl); !ok {
 				return false
 			}
-		} else if _, ok := prefixesIndex[syl]; !ok {
+		} else if _, ok := prefixByte(syl); !ok {
 			return false
 		}
 	}
@@ -470,41 +534,20 @@ func IsValidPat(name string) bool {
 	return !(sylsLen%2 != 0 && sylsLen != 1)
 }
 
-// IsValidPatp validates a @p string.
+// IsValidPatp validates a @p string: it must be in the canonical form that
+// Patp produces.
 func IsValidPatp(str string) bool {
 
-	dec, err := Patp2Dec(str)
-	if err != nil {
-		return false
-	}
-
-	p, err := Patp(dec)
-	if err != nil {
-		return false
-	}
-
-	return IsValidPat(str) && str == p
+	_, _, _, ok := scanName(str, false, nil)
+	return ok
 }
 
-// IsValidPatq validates a @q string.
+// IsValidPatq validates a @q string. Unlike @p, leading zero bytes are
+// allowed, as Hex2Patq preserves them.
 func IsValidPatq(str string) bool {
 
-	dec, err := Patq2Dec(str)
-	if err != nil {
-		return false
-	}
-
-	q, err := Patq(dec)
-	if err != nil {
-		return false
-	}
-
-	isValid, err := EqPatq(str, q)
-	if err != nil {
-		return false
-	}
-
-	return IsValidPat(str) && isValid
+	_, _, _, ok := scanName(str, true, nil)
+	return ok
 }
 
 func removeLeadingZeros(str string) string {
@@ -547,6 +590,10 @@ func Patp(arg string) (string, error) {
 		return "", fmt.Errorf(ugi.ErrInvalidInt, arg)
 	}
 
+	if v.IsUint64() {
+		return string(AppendPatp(nil, v.Uint64())), nil
+	}
+
 	sxz, err := ob.Fein(v.String())
 	if err != nil {
 		return "", err
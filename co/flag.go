@@ -0,0 +1,154 @@
+package co
+
+import (
+	"fmt"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+/*
+ShipFlag is a flag.Value, also usable with pflag, that accepts a single @p.
+
+Names must be canonical, as checked by IsValidPatp. If Classes is not empty,
+only ships of those classes are accepted, and if Sponsor is set, only ships
+descended from it. For example:
+
+	ship := &co.ShipFlag{Classes: []string{co.ShipClassPlanet, co.ShipClassMoon}}
+	flag.Var(ship, "ship", "a planet or moon")
+*/
+type ShipFlag struct {
+	Ship    Ship
+	Classes []string
+	// Sponsor, if not nil, must be one of the sponsors of the ship, found by
+	// following Sein up to a galaxy. A ship is not its own descendant.
+	Sponsor *Ship
+}
+
+// String returns the @p of the ship.
+func (f *ShipFlag) String() string {
+
+	if f == nil {
+		return ""
+	}
+
+	return f.Ship.String()
+}
+
+// Set parses and checks a @p.
+func (f *ShipFlag) Set(value string) error {
+
+	ship, err := checkShipFlag(value, f.Classes, f.Sponsor)
+	if err != nil {
+		return err
+	}
+
+	f.Ship = ship
+	return nil
+}
+
+// Type names the type of the flag for pflag.
+func (f *ShipFlag) Type() string {
+
+	return "ship"
+}
+
+// Get returns the ship, implementing flag.Getter.
+func (f *ShipFlag) Get() interface{} {
+
+	return f.Ship
+}
+
+// ShipListFlag is a ShipFlag that accepts any number of ships, either by
+// being repeated or as a comma-separated list.
+type ShipListFlag struct {
+	Ships   []Ship
+	Classes []string
+	Sponsor *Ship
+}
+
+// String returns the @p of every ship, separated by commas.
+func (f *ShipListFlag) String() string {
+
+	if f == nil {
+		return ""
+	}
+
+	names := make([]string, len(f.Ships))
+	for i, ship := range f.Ships {
+		names[i] = ship.String()
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Set parses and checks a comma-separated list of @p, adding them to Ships.
+// If any of them is rejected, none are added.
+func (f *ShipListFlag) Set(value string) error {
+
+	var ships []Ship
+	for _, name := range strings.Split(value, ",") {
+		ship, err := checkShipFlag(strings.TrimSpace(name), f.Classes, f.Sponsor)
+		if err != nil {
+			return err
+		}
+		ships = append(ships, ship)
+	}
+
+	f.Ships = append(f.Ships, ships...)
+	return nil
+}
+
+// Type names the type of the flag for pflag.
+func (f *ShipListFlag) Type() string {
+
+	return "ships"
+}
+
+// Get returns the ships, implementing flag.Getter.
+func (f *ShipListFlag) Get() interface{} {
+
+	return f.Ships
+}
+
+func checkShipFlag(value string, classes []string, sponsor *Ship) (Ship, error) {
+
+	ship, err := ParseShip(value)
+	if err != nil {
+		return Ship{}, err
+	}
+
+	if len(classes) > 0 {
+		clan := ship.Clan()
+		allowed := false
+		for _, class := range classes {
+			switch class {
+			case ShipClassGalaxy, ShipClassStar, ShipClassPlanet, ShipClassMoon, ShipClassComet:
+			default:
+				return Ship{}, fmt.Errorf(ugi.ErrUnknownShipClass, class)
+			}
+			allowed = allowed || class == clan
+		}
+		if !allowed {
+			return Ship{}, fmt.Errorf(ugi.ErrShipClass, ship, clan, strings.Join(classes, " or "))
+		}
+	}
+
+	if sponsor != nil {
+		chain := []string{ship.String()}
+		descended := false
+		for s := ship; s.Clan() != ShipClassGalaxy; {
+			s = s.Sein()
+			chain = append(chain, s.String())
+			if s == *sponsor {
+				descended = true
+				break
+			}
+		}
+		if !descended {
+			return Ship{}, fmt.Errorf(ugi.ErrShipSponsor, ship, sponsor, strings.Join(chain, " -> "))
+		}
+	}
+
+	return ship, nil
+}
@@ -0,0 +1,100 @@
+package co
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipFlag(t *testing.T) {
+
+	marzod := mustParseShip(t, "~marzod")
+
+	var testCases = []struct {
+		name  string
+		flag  *ShipFlag
+		value string
+		err   string
+	}{
+		{name: "any", flag: &ShipFlag{}, value: "~sampel-palnet"},
+		{name: "invalid", flag: &ShipFlag{}, value: "~sampel-palnez", err: "invalid @p: ~sampel-palnez"},
+		{name: "number", flag: &ShipFlag{}, value: "1624961343", err: "invalid @p: 1624961343"},
+		{
+			name:  "allowed class",
+			flag:  &ShipFlag{Classes: []string{ShipClassPlanet, ShipClassMoon}},
+			value: "~sampel-palnet",
+		},
+		{
+			name:  "disallowed class",
+			flag:  &ShipFlag{Classes: []string{ShipClassPlanet, ShipClassMoon}},
+			value: "~marzod",
+			err:   "~marzod is a star, but it must be a planet or moon",
+		},
+		{
+			name:  "unknown class",
+			flag:  &ShipFlag{Classes: []string{"planets"}},
+			value: "~marzod",
+			err:   "unknown ship class: planets",
+		},
+		{
+			name:  "descended",
+			flag:  &ShipFlag{Sponsor: &marzod},
+			value: "~doznec-wicdev-wisryt",
+		},
+		{
+			name:  "descended directly",
+			flag:  &ShipFlag{Sponsor: &marzod},
+			value: "~wicdev-wisryt",
+		},
+		{
+			name:  "not descended",
+			flag:  &ShipFlag{Sponsor: &marzod},
+			value: "~sampel-palnet",
+			err:   "~sampel-palnet is not descended from ~marzod: ~sampel-palnet -> ~talpur -> ~pur",
+		},
+		{
+			name:  "not its own descendant",
+			flag:  &ShipFlag{Sponsor: &marzod},
+			value: "~marzod",
+			err:   "~marzod is not descended from ~marzod: ~marzod -> ~zod",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(ioutil.Discard)
+			fs.Var(tt.flag, "ship", "")
+
+			err := fs.Parse([]string{"-ship", tt.value})
+			if tt.err != "" {
+				assert.EqualError(t, err, `invalid value "`+tt.value+`" for flag -ship: `+tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.value, tt.flag.String())
+				assert.Equal(t, mustParseShip(t, tt.value), tt.flag.Get())
+			}
+		})
+	}
+}
+
+func TestShipListFlag(t *testing.T) {
+
+	ships := &ShipListFlag{Classes: []string{ShipClassGalaxy, ShipClassStar}}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(ships, "ships", "")
+
+	assert.NoError(t, fs.Parse([]string{"-ships", "~zod,~nec", "-ships", "~marzod"}))
+	assert.Equal(t, "~zod,~nec,~marzod", ships.String())
+	assert.Equal(t, "ships", ships.Type())
+
+	assert.Error(t, ships.Set("~wes,~sampel-palnet"))
+	assert.Len(t, ships.Ships, 3)
+}
@@ -0,0 +1,108 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Reading is one interpretation of an atom literal under a single aura.
+type Reading struct {
+	Aura string
+	Atom *big.Int
+}
+
+// Literal is an atom literal recognized by Nuck.
+type Literal struct {
+	// Text is the literal as it was given.
+	Text string
+	// Reading is the interpretation Hoon would choose.
+	Reading
+	// Alternatives are the other auras the text is also valid under, in the
+	// order they were tried.
+	Alternatives []Reading
+}
+
+// Ambiguous reports whether the literal is valid under more than one aura.
+func (l Literal) Ambiguous() bool {
+
+	return len(l.Alternatives) > 0
+}
+
+// Explain describes how the literal was read and, if it is ambiguous, what
+// else it could have meant. A zero Literal has nothing to explain and gives
+// the empty string.
+func (l Literal) Explain() string {
+
+	if l.Atom == nil {
+		return ""
+	}
+
+	explanation := fmt.Sprintf("%s is @%s %s", l.Text, l.Aura, scotUd(l.Atom))
+	if !l.Ambiguous() {
+		return explanation
+	}
+
+	others := make([]string, len(l.Alternatives))
+	for i, alt := range l.Alternatives {
+		others[i] = fmt.Sprintf("@%s %s", alt.Aura, scotUd(alt.Atom))
+	}
+
+	return explanation + "; it is also valid as " + strings.Join(others, ", ")
+}
+
+/*
+Nuck detects the aura of an atom literal and parses it, like Hoon's nuck.
+
+Every registered aura is tried, in registration order, and the first one that
+accepts the text wins. The built-in auras are ordered so that the winner is
+the aura Hoon would pick, e.g. ~zod is read as @p rather than @q. Any other
+auras that also accept the text are reported as alternatives. A codec that
+panics or returns a nil atom is taken to reject the text, so one faulty custom
+aura can't break Nuck for every caller.
+*/
+func Nuck(text string) (Literal, error) {
+
+	lit := Literal{Text: text}
+	found := false
+
+	for _, aura := range auras.registered() {
+
+		_, codec, ok := auras.lookup(aura)
+		if !ok {
+			continue
+		}
+
+		atom, err := slaw(codec, text)
+		if err != nil || atom == nil {
+			continue
+		}
+
+		if !found {
+			lit.Reading = Reading{Aura: aura, Atom: atom}
+			found = true
+		} else {
+			lit.Alternatives = append(lit.Alternatives, Reading{Aura: aura, Atom: atom})
+		}
+	}
+
+	if !found {
+		return Literal{}, fmt.Errorf(ugi.ErrUnknownLiteral, text)
+	}
+
+	return lit, nil
+}
+
+// slaw parses text with a codec, turning a panic into an error.
+func slaw(codec AuraCodec, text string) (atom *big.Int, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			atom, err = nil, fmt.Errorf(ugi.ErrAuraPanic, r)
+		}
+	}()
+
+	return codec.Slaw(text)
+}
@@ -0,0 +1,108 @@
+package co
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNuck(t *testing.T) {
+
+	var testCases = []struct {
+		in           string
+		aura         string
+		atom         string
+		alternatives []string
+	}{
+		{in: "~zod", aura: "p", atom: "0", alternatives: []string{"q"}},
+		{in: "~dapnep-ronmyl", aura: "p", atom: "65536", alternatives: []string{"q"}},
+		{in: "~dozzod-dozzod", aura: "q", atom: "0"},
+		{in: "0x1f", aura: "ux", atom: "31"},
+		{in: "1.024", aura: "ud", atom: "1024"},
+		{in: "~2024.1.1", aura: "da", atom: "170141184506586659480305898381062963200"},
+		{in: "~s1", aura: "dr", atom: "18446744073709551616"},
+		{in: "~", aura: "n", atom: "0"},
+		{in: "%foo", aura: "tas", atom: "7303014"},
+		{in: "~.foo", aura: "ta", atom: "7303014"},
+		{in: "~~foo", aura: "t", atom: "7303014"},
+		{in: ".y", aura: "f", atom: "0"},
+		{in: ".1.2.3.4", aura: "if", atom: "16909060"},
+		{in: "-2", aura: "sd", atom: "3"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			lit, err := Nuck(tt.in)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.aura, lit.Aura)
+			assert.Equal(t, tt.atom, lit.Atom.String())
+			assert.Equal(t, len(tt.alternatives) > 0, lit.Ambiguous())
+			for i, aura := range tt.alternatives {
+				assert.Equal(t, aura, lit.Alternatives[i].Aura)
+			}
+		})
+	}
+}
+
+func TestNuckInvalid(t *testing.T) {
+
+	for _, in := range []string{"", "abc", "1024", "0x01", "~zo", "~nec-binwod"} {
+		t.Run(in, func(t *testing.T) {
+
+			_, err := Nuck(in)
+			assert.EqualError(t, err, "unrecognized atom literal: "+in)
+		})
+	}
+}
+
+func TestNuckPanickingCodec(t *testing.T) {
+
+	codec := AuraFuncs{
+		ScotFunc: func(atom *big.Int) string { return "" },
+		SlawFunc: func(text string) (*big.Int, error) { panic("broken codec") },
+	}
+	useTestAuras(t)
+	assert.NoError(t, RegisterAura("zw", codec))
+
+	lit, err := Nuck("~zod")
+	assert.NoError(t, err)
+	assert.Equal(t, "p", lit.Aura)
+
+	_, err = Nuck("abc")
+	assert.EqualError(t, err, "unrecognized atom literal: abc")
+}
+
+func TestNuckNilCodec(t *testing.T) {
+
+	codec := AuraFuncs{
+		ScotFunc: func(atom *big.Int) string { return "" },
+		SlawFunc: func(text string) (*big.Int, error) { return nil, nil },
+	}
+	useTestAuras(t)
+	assert.NoError(t, RegisterAura("zw", codec))
+
+	_, err := Nuck("abc")
+	assert.EqualError(t, err, "unrecognized atom literal: abc")
+
+	lit, err := Nuck("~zod")
+	assert.NoError(t, err)
+	assert.Equal(t, "~zod is @p 0; it is also valid as @q 0", lit.Explain())
+}
+
+func TestLiteralExplain(t *testing.T) {
+
+	lit, err := Nuck("~marzod")
+	assert.NoError(t, err)
+	assert.Equal(t, "~marzod is @p 256; it is also valid as @q 256", lit.Explain())
+
+	lit, err = Nuck("0x1.0000")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1.0000 is @ux 65.536", lit.Explain())
+
+	assert.Equal(t, "", Literal{}.Explain())
+}
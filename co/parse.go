@@ -0,0 +1,200 @@
+package co
+
+import (
+	"fmt"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// prefixLookup and suffixLookup map the sylKey of a syllable to one more than
+// its position in prefixes or suffixes, or to zero if it isn't one.
+var (
+	prefixLookup [26 * 26 * 26]uint16
+	suffixLookup [26 * 26 * 26]uint16
+)
+
+// sylKey packs three lowercase letters into an index of the lookup tables, or
+// returns -1 if syl is anything else.
+func sylKey(syl string) int {
+
+	if len(syl) != 3 {
+		return -1
+	}
+
+	key := 0
+	for i := 0; i < 3; i++ {
+		c := syl[i]
+		if c < 'a' || c > 'z' {
+			return -1
+		}
+		key = key*26 + int(c-'a')
+	}
+
+	return key
+}
+
+func prefixByte(syl string) (byte, bool) {
+
+	key := sylKey(syl)
+	if key < 0 || prefixLookup[key] == 0 {
+		return 0, false
+	}
+
+	return byte(prefixLookup[key] - 1), true
+}
+
+func suffixByte(syl string) (byte, bool) {
+
+	key := sylKey(syl)
+	if key < 0 || suffixLookup[key] == 0 {
+		return 0, false
+	}
+
+	return byte(suffixLookup[key] - 1), true
+}
+
+// prefixIndex and suffixIndex return the position of a syllable, or zero if
+// it isn't one.
+func prefixIndex(syl string) int {
+
+	b, _ := prefixByte(syl)
+	return int(b)
+}
+
+func suffixIndex(syl string) int {
+
+	b, _ := suffixByte(syl)
+	return int(b)
+}
+
+/*
+scanName checks, in a single pass, that name is laid out exactly the way Patp
+(or, if q is set, Patq) renders names: a lone suffix, or words made of a prefix
+and a suffix that are separated by dashes. @p separates groups of four words
+with a double dash and never has a leading zero word; @q uses single dashes
+throughout and may have leading zero words.
+
+It returns the number of bytes the name spells and the value of the last eight
+of them, and reports whether the rest were all zero. If out is not nil, every
+byte is written to it as well, most significant first.
+*/
+func scanName(name string, q bool, out []byte) (n int, v uint64, fits bool, ok bool) {
+
+	if len(name) < 4 || name[0] != '~' {
+		return 0, 0, false, false
+	}
+	s := name[1:]
+
+	if len(s) == 3 {
+		b, ok := suffixByte(s)
+		if !ok {
+			return 0, 0, false, false
+		}
+		if out != nil {
+			out[0] = b
+		}
+		return 1, uint64(b), true, true
+	}
+
+	letters := len(s) - strings.Count(s, "-")
+	if letters%6 != 0 {
+		return 0, 0, false, false
+	}
+	words := letters / 6
+	fits = true
+
+	pos := 0
+	for w := words - 1; w >= 0; w-- {
+
+		if pos+6 > len(s) {
+			return 0, 0, false, false
+		}
+		pb, okp := prefixByte(s[pos : pos+3])
+		sb, oks := suffixByte(s[pos+3 : pos+6])
+		if !okp || !oks {
+			return 0, 0, false, false
+		}
+		pos += 6
+
+		if !q && w == words-1 && pb == 0 && (words == 1 || sb == 0) {
+			return 0, 0, false, false
+		}
+
+		if v>>48 != 0 {
+			fits = false
+		}
+		v = v<<16 | uint64(pb)<<8 | uint64(sb)
+
+		if out != nil {
+			i := 2 * (words - 1 - w)
+			out[i], out[i+1] = pb, sb
+		}
+
+		if w > 0 {
+			sep := "-"
+			if !q && w%4 == 0 {
+				sep = "--"
+			}
+			if !strings.HasPrefix(s[pos:], sep) {
+				return 0, 0, false, false
+			}
+			pos += len(sep)
+		}
+	}
+
+	if pos != len(s) {
+		return 0, 0, false, false
+	}
+
+	return 2 * words, v, fits, true
+}
+
+// ParsePatp parses a @p-encoded string into the number it names, in a single
+// pass and without allocating. Only the canonical form that Patp produces is
+// accepted.
+func ParsePatp(name string) (uint64, error) {
+
+	_, v, fits, ok := scanName(name, false, nil)
+	if !ok {
+		return 0, fmt.Errorf(ugi.ErrInvalidP, name)
+	}
+	if !fits {
+		return 0, fmt.Errorf(ugi.ErrPatRange, "p", name)
+	}
+
+	return ob.Fynd64(v), nil
+}
+
+// ParsePatq parses a @q-encoded string into the number it names, in a single
+// pass and without allocating. Leading zero bytes are allowed, so long as the
+// value fits in 64 bits.
+func ParsePatq(name string) (uint64, error) {
+
+	_, v, fits, ok := scanName(name, true, nil)
+	if !ok {
+		return 0, fmt.Errorf(ugi.ErrInvalidQ, name)
+	}
+	if !fits {
+		return 0, fmt.Errorf(ugi.ErrPatRange, "q", name)
+	}
+
+	return v, nil
+}
+
+// ParsePatqBytes parses a @q-encoded string of any length into the bytes it
+// spells, most significant first. Leading zero bytes are preserved, as they
+// are by Patq2Hex.
+func ParsePatqBytes(name string) ([]byte, error) {
+
+	n, _, _, ok := scanName(name, true, nil)
+	if !ok {
+		return nil, fmt.Errorf(ugi.ErrInvalidQ, name)
+	}
+
+	buf := make([]byte, n)
+	scanName(name, true, buf)
+
+	return buf, nil
+}
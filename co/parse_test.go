@@ -0,0 +1,150 @@
+package co
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePatpRoundTrip(t *testing.T) {
+
+	values := []uint64{0, 1, 255, 256, 65535, 65536, 1624961343, 0xffffffff, 0x100000000, 0xffffffffffffffff}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		values = append(values, uint64(r.Uint32()), r.Uint64())
+	}
+
+	for _, v := range values {
+
+		p, err := ParsePatp(string(AppendPatp(nil, v)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, v, p)
+		}
+
+		q, err := ParsePatq(string(AppendPatq(nil, v)))
+		if assert.NoError(t, err) {
+			assert.Equal(t, v, q)
+		}
+	}
+}
+
+func TestParsePatp(t *testing.T) {
+
+	var testCases = []struct {
+		in  string
+		out uint64
+		err string
+	}{
+		{in: "~zod", out: 0},
+		{in: "~marzod", out: 256},
+		{in: "~sampel-palnet", out: 1624961343},
+		{in: "~dapnep-ronmyl-sampel-palnet", out: 4773359030139285823},
+		{in: "~doznec", err: "invalid @p: ~doznec"},
+		{in: "~dozzod-marzod", err: "invalid @p: ~dozzod-marzod"},
+		{in: "~sampelpalnet", err: "invalid @p: ~sampelpalnet"},
+		{in: "~sampel--palnet", err: "invalid @p: ~sampel--palnet"},
+		{in: "~sampel-palnet-", err: "invalid @p: ~sampel-palnet-"},
+		{in: "~sampel-palnez", err: "invalid @p: ~sampel-palnez"},
+		{in: "~Sampel-palnet", err: "invalid @p: ~Sampel-palnet"},
+		{in: "~nec-binwod", err: "invalid @p: ~nec-binwod"},
+		{in: "sampel-palnet", err: "invalid @p: sampel-palnet"},
+		{in: "~", err: "invalid @p: ~"},
+		{
+			in:  "~doznec--dapnep-ronmyl-sampel-palnet",
+			err: "@p out of range for 64 bits: ~doznec--dapnep-ronmyl-sampel-palnet",
+		},
+		{
+			in:  "~doznec-dapnep-ronmyl-sampel-palnet",
+			err: "invalid @p: ~doznec-dapnep-ronmyl-sampel-palnet",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			v, err := ParsePatp(tt.in)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Equal(t, strings.HasPrefix(tt.err, "@p out of range"), IsValidPatp(tt.in))
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.out, v)
+				assert.True(t, IsValidPatp(tt.in))
+			}
+		})
+	}
+}
+
+func TestParsePatq(t *testing.T) {
+
+	var testCases = []struct {
+		in  string
+		out uint64
+		err string
+	}{
+		{in: "~zod", out: 0},
+		{in: "~doznec", out: 1},
+		{in: "~marzod", out: 256},
+		{in: "~dozzod-dozzod-dozzod-dozzod-doznec", out: 1},
+		{in: "~sampel-palnet", out: 0x46f7f4f},
+		{in: "~sampel--palnet", err: "invalid @q: ~sampel--palnet"},
+		{in: "~nec-binwod", err: "invalid @q: ~nec-binwod"},
+		{
+			in:  "~doznec-dozzod-dozzod-dozzod-dozzod",
+			err: "@q out of range for 64 bits: ~doznec-dozzod-dozzod-dozzod-dozzod",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			v, err := ParsePatq(tt.in)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.out, v)
+				assert.True(t, IsValidPatq(tt.in))
+			}
+		})
+	}
+}
+
+func TestParsePatqBytes(t *testing.T) {
+
+	buf, err := ParsePatqBytes("~dozzod-doznec-sampel-palnet-sampel-palnet")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0, 0, 0, 1, 4, 0x6f, 0x7f, 0x4f, 4, 0x6f, 0x7f, 0x4f}, buf)
+	}
+
+	buf, err = ParsePatqBytes("~nec")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{1}, buf)
+	}
+
+	_, err = ParsePatqBytes("~nec-")
+	assert.EqualError(t, err, "invalid @q: ~nec-")
+}
+
+func TestParsePatpAllocations(t *testing.T) {
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = ParsePatp("~dapnep-ronmyl-sampel-palnet")
+		_, _ = ParsePatq("~dapnep-ronmyl-sampel-palnet")
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkParsePatp(b *testing.B) {
+
+	for i := 0; i < b.N; i++ {
+		_, _ = ParsePatp("~dapnep-ronmyl-sampel-palnet")
+	}
+}
@@ -0,0 +1,251 @@
+package co
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+/*
+Ship is an Urbit ship. It is stored as its canonical @p, so ships can be
+compared with == and used as map keys. The zero value is ~zod.
+
+A Ship implements encoding.TextMarshaler, json.Marshaler, sql.Scanner,
+driver.Valuer and encoding.BinaryMarshaler, which gob uses. Everywhere but in
+binary it is written as its @p; ShipNumber writes it as a number instead.
+Every decoder accepts a @p or a decimal number and rejects anything that
+isn't a valid ship.
+*/
+type Ship struct {
+	name string
+	// num is the number of the ship, kept so that it isn't parsed from name
+	// every time, unless comet is set because it doesn't fit.
+	num   uint64
+	comet bool
+}
+
+// ShipNumber is a Ship that is written to JSON as a number and to SQL as an
+// integer.
+type ShipNumber struct {
+	Ship
+}
+
+// ParseShip parses a @p. Only the canonical form that Patp produces is
+// accepted.
+func ParseShip(name string) (Ship, error) {
+
+	if !IsValidPatp(name) {
+		return Ship{}, fmt.Errorf(ugi.ErrInvalidP, name)
+	}
+
+	return newShip(name), nil
+}
+
+// newShip returns the ship with a name known to be valid. ~zod is always
+// stored as the zero value, and every other field follows from the name, so
+// that == works.
+func newShip(name string) Ship {
+
+	if name == "~zod" {
+		return Ship{}
+	}
+
+	v, err := ParsePatp(name)
+	return Ship{name: name, num: v, comet: err != nil}
+}
+
+// ShipFromUint64 returns the ship with the given number.
+func ShipFromUint64(v uint64) Ship {
+
+	if v == 0 {
+		return Ship{}
+	}
+
+	return Ship{name: string(AppendPatp(nil, v)), num: v}
+}
+
+// ShipFromBig returns the ship with the given number, which must not be nil or
+// negative.
+func ShipFromBig(v *big.Int) (Ship, error) {
+
+	if v == nil || v.Sign() < 0 {
+		return Ship{}, fmt.Errorf(ugi.ErrInvalidInt, v.String())
+	}
+
+	p, err := Patp(v.String())
+	if err != nil {
+		return Ship{}, err
+	}
+
+	return newShip(p), nil
+}
+
+// parseShipText reads a ship from either its @p or its decimal number.
+func parseShipText(text string) (Ship, error) {
+
+	if len(text) > 0 && text[0] == '~' {
+		return ParseShip(text)
+	}
+
+	v, ok := big.NewInt(0).SetString(text, 10)
+	if !ok || len(text) == 0 || text[0] < '0' || text[0] > '9' {
+		return Ship{}, fmt.Errorf(ugi.ErrInvalidInt, text)
+	}
+
+	return ShipFromBig(v)
+}
+
+// String returns the @p of the ship.
+func (s Ship) String() string {
+
+	if s.name == "" {
+		return "~zod"
+	}
+
+	return s.name
+}
+
+// Big returns the number of the ship.
+func (s Ship) Big() *big.Int {
+
+	if v, ok := s.Uint64(); ok {
+		return big.NewInt(0).SetUint64(v)
+	}
+
+	// Only valid names are ever stored.
+	v, _ := patp2bn(s.name)
+	return v
+}
+
+// Uint64 returns the number of the ship, and whether it fits in a uint64,
+// which it does for every ship but comets.
+func (s Ship) Uint64() (uint64, bool) {
+
+	return s.num, !s.comet
+}
+
+// Clan returns the class of the ship.
+func (s Ship) Clan() string {
+
+	clan, _ := Clan(s.String())
+	return clan
+}
+
+// Sein returns the sponsor of the ship.
+func (s Ship) Sein() Ship {
+
+	sein, _ := Sein(s.String())
+	return newShip(sein)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Ship) MarshalText() ([]byte, error) {
+
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Ship) UnmarshalText(text []byte) error {
+
+	ship, err := parseShipText(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = ship
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Ship) MarshalJSON() ([]byte, error) {
+
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Like the standard types, it
+// leaves the ship unchanged if data is null.
+func (s *Ship) UnmarshalJSON(data []byte) error {
+
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		data = []byte(text)
+	}
+
+	return s.UnmarshalText(data)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s ShipNumber) MarshalJSON() ([]byte, error) {
+
+	return []byte(s.Big().String()), nil
+}
+
+// Value implements driver.Valuer.
+func (s Ship) Value() (driver.Value, error) {
+
+	return s.String(), nil
+}
+
+// Value implements driver.Valuer. Ships that don't fit in an int64 are
+// written as decimal text, which numeric columns accept.
+func (s ShipNumber) Value() (driver.Value, error) {
+
+	if v, ok := s.Uint64(); ok && v <= 1<<63-1 {
+		return int64(v), nil
+	}
+
+	return s.Big().String(), nil
+}
+
+// Scan implements sql.Scanner. Unlike JSON null, which leaves the ship
+// unchanged, a NULL is an error, as it is for the other types database/sql
+// scans into: leaving the ship unchanged would silently keep the value of an
+// earlier row. Scan a nullable column into a *Ship, which NULL sets to nil.
+func (s *Ship) Scan(src interface{}) error {
+
+	switch src := src.(type) {
+	case int64:
+		if src < 0 {
+			return fmt.Errorf(ugi.ErrInvalidInt, strconv.FormatInt(src, 10))
+		}
+		*s = ShipFromUint64(uint64(src))
+		return nil
+	case string:
+		return s.UnmarshalText([]byte(src))
+	case []byte:
+		return s.UnmarshalText(src)
+	}
+
+	return fmt.Errorf(ugi.ErrShipScan, src)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, writing the number of
+// the ship as big-endian bytes.
+func (s Ship) MarshalBinary() ([]byte, error) {
+
+	return s.Big().Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *Ship) UnmarshalBinary(data []byte) error {
+
+	ship, err := ShipFromBig(big.NewInt(0).SetBytes(data))
+	if err != nil {
+		return err
+	}
+
+	*s = ship
+	return nil
+}
@@ -0,0 +1,61 @@
+package co
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/*
+Format implements fmt.Formatter:
+
+	%s, %v  the @p, e.g. ~sampel-palnet
+	%d      the number in decimal
+	%x, %X  the number in hexadecimal; %#x adds the 0x prefix
+	%q      the @q of the number
+	%+v     a debug form with the number, class and sponsor
+
+Widths and the - flag pad the output as usual.
+*/
+func (s Ship) Format(f fmt.State, verb rune) {
+
+	switch verb {
+	case 'd', 'x', 'X':
+		fmt.Fprintf(f, directive(f, verb), s.Big())
+	case 'q':
+		q, _ := Patq(s.Big().String())
+		fmt.Fprintf(f, directive(f, 's'), q)
+	case 'v':
+		if f.Flag('+') {
+			debug := fmt.Sprintf("%s (%d, %s, sponsor %s)", s, s.Big(), s.Clan(), s.Sein())
+			fmt.Fprintf(f, directive(f, 's'), debug)
+			return
+		}
+		fallthrough
+	case 's':
+		fmt.Fprintf(f, directive(f, 's'), s.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(co.Ship=%s)", verb, s.String())
+	}
+}
+
+// directive rebuilds the formatting directive f was created from, with the
+// + flag dropped and the given verb.
+func directive(f fmt.State, verb rune) string {
+
+	d := []byte{'%'}
+	for _, flag := range "-# 0" {
+		if f.Flag(int(flag)) {
+			d = append(d, byte(flag))
+		}
+	}
+
+	if width, ok := f.Width(); ok {
+		d = strconv.AppendInt(d, int64(width), 10)
+	}
+	if prec, ok := f.Precision(); ok {
+		d = append(d, '.')
+		d = strconv.AppendInt(d, int64(prec), 10)
+	}
+
+	return string(append(d, string(verb)...))
+}
@@ -0,0 +1,40 @@
+package co
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipFormat(t *testing.T) {
+
+	ship := ShipFromUint64(1624961343)
+
+	var testCases = []struct {
+		format string
+		out    string
+	}{
+		{format: "%s", out: "~sampel-palnet"},
+		{format: "%v", out: "~sampel-palnet"},
+		{format: "%d", out: "1624961343"},
+		{format: "%x", out: "60daf13f"},
+		{format: "%#X", out: "0X60DAF13F"},
+		{format: "%q", out: "~ronler-talpur"},
+		{format: "%+v", out: "~sampel-palnet (1624961343, planet, sponsor ~talpur)"},
+		{format: "[%16s]", out: "[  ~sampel-palnet]"},
+		{format: "[%-16s]", out: "[~sampel-palnet  ]"},
+		{format: "[%012d]", out: "[001624961343]"},
+		{format: "%t", out: "%!t(co.Ship=~sampel-palnet)"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.format, func(t *testing.T) {
+
+			assert.Equal(t, tt.out, fmt.Sprintf(tt.format, ship))
+			assert.Equal(t, tt.out, fmt.Sprintf(tt.format, ShipNumber{ship}))
+		})
+	}
+
+	assert.Equal(t, "~zod 0 ~zod", fmt.Sprintf("%s %d %q", Ship{}, Ship{}, Ship{}))
+}
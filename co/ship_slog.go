@@ -0,0 +1,25 @@
+//go:build go1.21
+// +build go1.21
+
+package co
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer, logging the ship as a group of its
+// name, number and class. Comets' numbers don't fit in a uint64, so they are
+// logged as decimal strings.
+func (s Ship) LogValue() slog.Value {
+
+	number := slog.String("number", s.Big().String())
+	if v, ok := s.Uint64(); ok {
+		number = slog.Uint64("number", v)
+	}
+
+	return slog.GroupValue(
+		slog.String("name", s.String()),
+		number,
+		slog.String("clan", s.Clan()),
+	)
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+package co
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShipLogValue(t *testing.T) {
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	logger.Info("boot", "ship", ShipFromUint64(1624961343), "comet", mustParseShip(t, cometName))
+	assert.JSONEq(t, `{
+		"level": "INFO",
+		"msg": "boot",
+		"ship": {"name": "~sampel-palnet", "number": 1624961343, "clan": "planet"},
+		"comet": {"name": "`+cometName+`", "number": "`+mustParseShip(t, cometName).Big().String()+`", "clan": "comet"}
+	}`, buf.String())
+}
@@ -0,0 +1,146 @@
+package co
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const cometName string = "~dasres-ragnep-lislyt-ritpur--fipfes-dortec-togtun-macnyx"
+
+func TestShip(t *testing.T) {
+
+	var zero Ship
+	assert.Equal(t, "~zod", zero.String())
+	assert.Equal(t, ShipFromUint64(0), mustParseShip(t, "~zod"))
+
+	ship := ShipFromUint64(1624961343)
+	assert.Equal(t, "~sampel-palnet", ship.String())
+	assert.Equal(t, ship, mustParseShip(t, "~sampel-palnet"))
+	assert.Equal(t, big.NewInt(1624961343), ship.Big())
+	assert.Equal(t, ShipClassPlanet, ship.Clan())
+	v, ok := ship.Uint64()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1624961343), v)
+	assert.True(t, ShipFromUint64(256) == mustParseShip(t, "~marzod"))
+	assert.Equal(t, "~talpur", ship.Sein().String())
+
+	comet := mustParseShip(t, cometName)
+	_, ok = comet.Uint64()
+	assert.False(t, ok)
+	fromBig, err := ShipFromBig(comet.Big())
+	assert.NoError(t, err)
+	assert.Equal(t, comet, fromBig)
+
+	_, err = ParseShip("~doznec")
+	assert.EqualError(t, err, "invalid @p: ~doznec")
+
+	_, err = ShipFromBig(big.NewInt(-1))
+	assert.EqualError(t, err, "invalid integer string: -1")
+
+	_, err = ShipFromBig(nil)
+	assert.EqualError(t, err, "invalid integer string: <nil>")
+}
+
+func mustParseShip(t *testing.T, name string) Ship {
+
+	ship, err := ParseShip(name)
+	assert.NoError(t, err)
+	return ship
+}
+
+func TestShipJSON(t *testing.T) {
+
+	type record struct {
+		Ship   Ship       `json:"ship"`
+		Number ShipNumber `json:"number"`
+		Map    map[Ship]int
+	}
+
+	in := record{
+		Ship:   ShipFromUint64(256),
+		Number: ShipNumber{ShipFromUint64(1624961343)},
+		Map:    map[Ship]int{ShipFromUint64(0): 1},
+	}
+
+	data, err := json.Marshal(in)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ship":"~marzod","number":1624961343,"Map":{"~zod":1}}`, string(data))
+
+	var out record
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	// Either form is accepted by either type.
+	assert.NoError(t, json.Unmarshal([]byte(`{"ship":256,"number":"~sampel-palnet"}`), &out))
+	assert.Equal(t, in.Ship, out.Ship)
+	assert.Equal(t, in.Number, out.Number)
+
+	// null leaves either type unchanged.
+	assert.NoError(t, json.Unmarshal([]byte(`{"ship":null,"number":null}`), &out))
+	assert.Equal(t, in.Ship, out.Ship)
+	assert.Equal(t, in.Number, out.Number)
+
+	var testCases = []struct {
+		in  string
+		err string
+	}{
+		{in: `{"ship":"~doznec"}`, err: "invalid @p: ~doznec"},
+		{in: `{"ship":"sampel-palnet"}`, err: "invalid integer string: sampel-palnet"},
+		{in: `{"ship":-1}`, err: "invalid integer string: -1"},
+		{in: `{"number":1.5}`, err: "invalid integer string: 1.5"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			var out record
+			assert.EqualError(t, json.Unmarshal([]byte(tt.in), &out), tt.err)
+		})
+	}
+}
+
+func TestShipSQL(t *testing.T) {
+
+	ship := ShipFromUint64(1624961343)
+
+	value, err := ship.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, driver.Value("~sampel-palnet"), value)
+
+	value, err = ShipNumber{ship}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, driver.Value(int64(1624961343)), value)
+
+	value, err = ShipNumber{ShipFromUint64(1 << 63)}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, driver.Value("9223372036854775808"), value)
+
+	for _, src := range []interface{}{int64(1624961343), "~sampel-palnet", []byte("1624961343")} {
+		var scanned ShipNumber
+		assert.NoError(t, scanned.Scan(src))
+		assert.Equal(t, ship, scanned.Ship)
+	}
+
+	var scanned Ship
+	assert.EqualError(t, scanned.Scan(nil), "cannot scan <nil> into a ship")
+	assert.EqualError(t, scanned.Scan(int64(-1)), "invalid integer string: -1")
+	assert.EqualError(t, scanned.Scan("~sampel-palnez"), "invalid @p: ~sampel-palnez")
+}
+
+func TestShipGob(t *testing.T) {
+
+	in := []Ship{{}, ShipFromUint64(1624961343), mustParseShip(t, cometName)}
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(in))
+
+	var out []Ship
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+	assert.Equal(t, in, out)
+}
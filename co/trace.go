@@ -0,0 +1,166 @@
+package co
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+// Syllable is one syllable of a @p name.
+type Syllable struct {
+	Text string
+	// Prefix reports whether the syllable is from Prefixes rather than
+	// Suffixes, and Index is its position there.
+	Prefix bool
+	Index  int
+}
+
+// PatpTrace records every step of converting between a number and its @p.
+type PatpTrace struct {
+	Number *big.Int
+	// Scramble is the trace of Fein when encoding, or Fynd when decoding.
+	Scramble *ob.Trace
+	// Syllables spell out the scrambled value, most significant first.
+	Syllables []Syllable
+	Patp      string
+}
+
+// TracePatp converts a number to a @p-encoded string like Patp, recording
+// every intermediate value.
+func TracePatp(arg string) (*PatpTrace, error) {
+
+	v, ok := big.NewInt(0).SetString(arg, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
+	}
+
+	p, err := Patp(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &PatpTrace{Number: v, Scramble: ob.TraceFein(v), Patp: p}
+	sxz := t.Scramble.Out
+
+	if met(three, sxz, nil).Cmp(one) <= 0 {
+		idx := int(sxz.Int64())
+		t.Syllables = []Syllable{{Text: suffixes[idx], Index: idx}}
+		return t, nil
+	}
+
+	for i := int(met(four, sxz, nil).Int64()) - 1; i >= 0; i-- {
+		log := end(four, one, rsh(four, big.NewInt(int64(i)), sxz))
+		pre := int(rsh(three, one, log).Int64())
+		suf := int(end(three, one, log).Int64())
+		t.Syllables = append(t.Syllables,
+			Syllable{Text: prefixes[pre], Prefix: true, Index: pre},
+			Syllable{Text: suffixes[suf], Index: suf},
+		)
+	}
+
+	return t, nil
+}
+
+// TracePatp2Dec converts a @p-encoded string to a number like Patp2Dec,
+// recording every intermediate value.
+func TracePatp2Dec(name string) (*PatpTrace, error) {
+
+	if !IsValidPat(name) {
+		return nil, fmt.Errorf(ugi.ErrInvalidP, name)
+	}
+
+	t := &PatpTrace{Patp: name}
+	syls := patp2syls(name)
+
+	addr := big.NewInt(0)
+	for i, syl := range syls {
+		s := Syllable{Text: syl}
+		if i%2 != 0 || len(syls) == 1 {
+			s.Index = suffixIndex(syl)
+		} else {
+			s.Prefix = true
+			s.Index = prefixIndex(syl)
+		}
+		t.Syllables = append(t.Syllables, s)
+		addr.Lsh(addr, 8).Or(addr, big.NewInt(int64(s.Index)))
+	}
+
+	t.Scramble = ob.TraceFynd(addr)
+	t.Number = t.Scramble.Out
+
+	return t, nil
+}
+
+// Explain describes each step of the trace, one per line, in the order they
+// were taken.
+func (t *PatpTrace) Explain() string {
+
+	var b strings.Builder
+
+	if t.Scramble.Inverse {
+		fmt.Fprintf(&b, "%s is @p %s\n", t.Patp, t.Number)
+		t.explainSyllables(&b)
+		explainScramble(&b, t.Scramble)
+	} else {
+		fmt.Fprintf(&b, "@p of %s is %s\n", t.Number, t.Patp)
+		explainScramble(&b, t.Scramble)
+		t.explainSyllables(&b)
+	}
+
+	return b.String()
+}
+
+func (t *PatpTrace) explainSyllables(b *strings.Builder) {
+
+	if t.Scramble.Inverse {
+		fmt.Fprintf(b, "syllables of %s spell 0x%x:\n", t.Patp, t.Scramble.In)
+	} else {
+		fmt.Fprintf(b, "syllables of 0x%x:\n", t.Scramble.Out)
+	}
+
+	for _, s := range t.Syllables {
+		list := "suffix"
+		if s.Prefix {
+			list = "prefix"
+		}
+		fmt.Fprintf(b, "  %s is %s 0x%02x\n", s.Text, list, s.Index)
+	}
+}
+
+func explainScramble(b *strings.Builder, t *ob.Trace) {
+
+	name, cipher := "fein", "feis"
+	if t.Inverse {
+		name, cipher = "fynd", "tail"
+	}
+
+	fmt.Fprintf(b, "%s 0x%x:\n", name, t.In)
+	if !t.Scrambled {
+		if t.Hi == nil {
+			fmt.Fprintf(b, "  0x%x is not scrambled, since it has more than 64 bits\n", t.In)
+		} else {
+			fmt.Fprintf(b, "  0x%x is not scrambled, since its low 32 bits are below 0x10000\n", t.In)
+		}
+		return
+	}
+
+	fmt.Fprintf(b, "  split into hi 0x%x and lo 0x%x\n", t.Hi, t.Lo)
+	fmt.Fprintf(b, "  %s scrambles lo - 0x10000 = 0x%x\n", cipher, t.Passes[0].In)
+
+	for i, pass := range t.Passes {
+		if i > 0 {
+			fmt.Fprintf(b, "  0x%x is not below 0xffffffff, so it is scrambled again\n", t.Passes[i-1].Out)
+		}
+		fmt.Fprintf(b, "  pass %d: ell 0x%x, arr 0x%x\n", i+1, pass.Ell, pass.Arr)
+		for _, r := range pass.Rounds {
+			fmt.Fprintf(b, "    round %d: muk(0x%08x, 0x%x) = 0x%x; ell 0x%x, arr 0x%x\n",
+				r.Round, r.Key, r.Arg, r.Muk, r.Ell, r.Arr)
+		}
+		fmt.Fprintf(b, "    result 0x%x\n", pass.Out)
+	}
+
+	fmt.Fprintf(b, "  hi | (0x10000 + 0x%x) = 0x%x\n", t.Passes[len(t.Passes)-1].Out, t.Out)
+}
@@ -0,0 +1,73 @@
+package co
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracePatp(t *testing.T) {
+
+	for _, in := range []string{"0", "255", "256", "65535", "65536", "1624961343", "4294967295", "4773359030139285823", "18446744073709551616"} {
+		t.Run(in, func(t *testing.T) {
+
+			p, err := Patp(in)
+			assert.NoError(t, err)
+
+			trace, err := TracePatp(in)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, p, trace.Patp)
+			assert.Equal(t, in, trace.Number.String())
+
+			var spelled string
+			for _, s := range trace.Syllables {
+				spelled += s.Text
+			}
+			assert.Equal(t, patp2syls(p), patp2syls("~"+spelled))
+
+			back, err := TracePatp2Dec(p)
+			if assert.NoError(t, err) {
+				assert.Equal(t, in, back.Number.String())
+				assert.Equal(t, trace.Syllables, back.Syllables)
+				assert.Equal(t, trace.Scramble.Out, back.Scramble.In)
+			}
+		})
+	}
+}
+
+func TestTracePatpInvalid(t *testing.T) {
+
+	_, err := TracePatp("-1")
+	assert.EqualError(t, err, "invalid integer string: -1")
+
+	_, err = TracePatp2Dec("~sampel-palnez")
+	assert.EqualError(t, err, "invalid @p: ~sampel-palnez")
+}
+
+func TestPatpTraceExplain(t *testing.T) {
+
+	trace, err := TracePatp("1624961343")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, `@p of 1624961343 is ~sampel-palnet
+fein 0x60daf13f:
+  split into hi 0x0 and lo 0x60daf13f
+  feis scrambles lo - 0x10000 = 0x60d9f13f
+  pass 1: ell 0x5219, arr 0x60da
+    round 1: muk(0xb76d5eed, 0x60da) = 0xc8b010b2; ell 0x60da, arr 0x2b7c
+    round 2: muk(0xee281300, 0x2b7c) = 0xe491e92a; ell 0x2b7c, arr 0x4a04
+    round 3: muk(0x85bcae01, 0x4a04) = 0x48e4900d; ell 0x4a04, arr 0x46e
+    round 4: muk(0x4b387af7, 0x46e) = 0x81e939b9; ell 0x46e, arr 0x83bd
+    result 0x46e7f4f
+  hi | (0x10000 + 0x46e7f4f) = 0x46f7f4f
+syllables of 0x46f7f4f:
+  sam is prefix 0x04
+  pel is suffix 0x6f
+  pal is prefix 0x7f
+  net is suffix 0x4f
+`, trace.Explain())
+}
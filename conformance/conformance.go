@@ -0,0 +1,239 @@
+/*
+Package conformance generates and checks test vectors for @p and @q
+encodings, so that this implementation can be compared with urbit-ob, Hoon
+and anything else that can export the same vector file.
+
+A vector file is JSON: an object with a format version, the seed its random
+samples were drawn with, and a list of vectors, one per line. Every vector
+gives a number in decimal along with its @p, @q, hex, clan and sein.
+
+The vectors.json file in testdata was written by this implementation, so it is
+a regression snapshot rather than a conformance source: checking it only shows
+that the encodings have not changed. The known.json file next to it is the
+independent check, a handful of well-known pairs such as 256 and ~marzod or
+1624961343 and ~sampel-palnet, written by hand from the urbit-ob documentation
+and Hoon's +scot. Wider conformance needs a file exported from urbit-ob or
+Hoon.
+*/
+package conformance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Version is the format version of the vector files written by Write.
+const Version int = 1
+
+// Vector is the expected encoding of a single number.
+type Vector struct {
+	Number string `json:"number"`
+	Patp   string `json:"patp"`
+	Patq   string `json:"patq"`
+	// Hex is the number as Patp2Hex gives it: lowercase, without a prefix and
+	// padded to an even length.
+	Hex  string `json:"hex"`
+	Clan string `json:"clan"`
+	Sein string `json:"sein"`
+}
+
+// File is the contents of a vector file.
+type File struct {
+	Version int      `json:"version"`
+	Seed    int64    `json:"seed"`
+	Vectors []Vector `json:"vectors"`
+}
+
+// Mismatch is a value of a vector that this implementation disagrees with.
+type Mismatch struct {
+	// Index is the position of the vector in the file.
+	Index    int
+	Number   string
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (m Mismatch) String() string {
+
+	return fmt.Sprintf("vector %d (%s): %s is %q, expected %q", m.Index, m.Number, m.Field, m.Actual, m.Expected)
+}
+
+// classBits are the bit lengths of the largest galaxy, star, planet, moon and
+// comet. Random samples are drawn evenly from each class.
+var classBits = []uint{8, 16, 32, 64, 128}
+
+// edgeCases are the numbers every vector file starts with: the boundaries of
+// each class and of the ranges Fein scrambles.
+func edgeCases() []*big.Int {
+
+	var edges []*big.Int
+	for _, bits := range classBits {
+		limit := big.NewInt(0).Lsh(big.NewInt(1), bits)
+		edges = append(edges,
+			big.NewInt(0).Sub(limit, big.NewInt(1)),
+			limit,
+			big.NewInt(0).Add(limit, big.NewInt(1)),
+		)
+	}
+
+	edges = append([]*big.Int{big.NewInt(0), big.NewInt(1)}, edges...)
+	for _, v := range []uint64{0xfffeffff, 0xffff0000, 0x10000ffff, 0x100010000} {
+		edges = append(edges, big.NewInt(0).SetUint64(v))
+	}
+
+	return edges
+}
+
+// Compute returns the vector for a number as this implementation encodes it.
+func Compute(number *big.Int) (Vector, error) {
+
+	if number.Sign() < 0 {
+		return Vector{}, fmt.Errorf(ugi.ErrInvalidInt, number.String())
+	}
+
+	v := Vector{Number: number.String()}
+
+	var err error
+	if v.Patp, err = co.Patp(v.Number); err != nil {
+		return Vector{}, err
+	}
+	if v.Patq, err = co.Patq(v.Number); err != nil {
+		return Vector{}, err
+	}
+	if v.Hex, err = co.Patp2Hex(v.Patp); err != nil {
+		return Vector{}, err
+	}
+	if v.Clan, err = co.Clan(v.Patp); err != nil {
+		return Vector{}, err
+	}
+	if v.Sein, err = co.Sein(v.Patp); err != nil {
+		return Vector{}, err
+	}
+
+	return v, nil
+}
+
+// Generate returns the vectors for every edge case followed by samples random
+// numbers of each class of ship, drawn from a source seeded with seed.
+func Generate(seed int64, samples int) (*File, error) {
+
+	numbers := edgeCases()
+
+	r := rand.New(rand.NewSource(seed))
+	low := big.NewInt(0)
+	for _, bits := range classBits {
+		high := big.NewInt(0).Lsh(big.NewInt(1), bits)
+		span := big.NewInt(0).Sub(high, low)
+		for i := 0; i < samples; i++ {
+			numbers = append(numbers, big.NewInt(0).Add(low, big.NewInt(0).Rand(r, span)))
+		}
+		low = high
+	}
+
+	file := &File{Version: Version, Seed: seed, Vectors: make([]Vector, len(numbers))}
+	for i, number := range numbers {
+		v, err := Compute(number)
+		if err != nil {
+			return nil, err
+		}
+		file.Vectors[i] = v
+	}
+
+	return file, nil
+}
+
+// Write writes a vector file in its canonical form, with one vector per line,
+// so that files from different implementations can be diffed.
+func Write(w io.Writer, file *File) error {
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "{\n  \"version\": %d,\n  \"seed\": %d,\n  \"vectors\": [", file.Version, file.Seed)
+
+	for i, v := range file.Vectors {
+		line, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString("\n    ")
+		bw.Write(line)
+	}
+
+	bw.WriteString("\n  ]\n}\n")
+	return bw.Flush()
+}
+
+// Read reads a vector file.
+func Read(r io.Reader) (*File, error) {
+
+	var file File
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	if file.Version != Version {
+		return nil, fmt.Errorf(ugi.ErrVectorVersion, file.Version)
+	}
+
+	return &file, nil
+}
+
+// Check compares every vector of a file with this implementation, in both
+// directions, and returns everything it disagrees with.
+func Check(file *File) []Mismatch {
+
+	var mismatches []Mismatch
+	for i, expected := range file.Vectors {
+
+		report := func(field, want, got string) {
+			if want != got {
+				mismatches = append(mismatches, Mismatch{
+					Index: i, Number: expected.Number, Field: field, Expected: want, Actual: got,
+				})
+			}
+		}
+
+		number, ok := big.NewInt(0).SetString(expected.Number, 10)
+		if !ok {
+			report("number", expected.Number, "not a number")
+			continue
+		}
+
+		actual, err := Compute(number)
+		if err != nil {
+			report("number", expected.Number, err.Error())
+			continue
+		}
+
+		report("patp", expected.Patp, actual.Patp)
+		report("patq", expected.Patq, actual.Patq)
+		report("hex", expected.Hex, actual.Hex)
+		report("clan", expected.Clan, actual.Clan)
+		report("sein", expected.Sein, actual.Sein)
+
+		report("patp2dec", expected.Number, decode(co.Patp2Dec, expected.Patp))
+		report("patq2dec", expected.Number, decode(co.Patq2Dec, expected.Patq))
+	}
+
+	return mismatches
+}
+
+func decode(f func(string) (string, error), name string) string {
+
+	dec, err := f(name)
+	if err != nil {
+		return err.Error()
+	}
+
+	return dec
+}
@@ -0,0 +1,103 @@
+package conformance
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/vectors.json")
+
+// goldenFile is a regression snapshot written by Generate, not vectors from a
+// reference implementation.
+const goldenFile string = "vectors.json"
+
+func TestGenerateMatchesGolden(t *testing.T) {
+
+	file, err := Generate(1, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, file))
+
+	golden := filepath.Join("testdata", goldenFile)
+	if *update {
+		assert.NoError(t, ioutil.WriteFile(golden, buf.Bytes(), 0644))
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if assert.NoError(t, err) {
+		assert.Equal(t, string(expected), buf.String())
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+
+	f, err := os.Open(filepath.Join("testdata", goldenFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	file, err := Read(f)
+	if assert.NoError(t, err) {
+		assert.Len(t, file.Vectors, 71)
+		assert.Empty(t, Check(file))
+	}
+}
+
+// knownFile holds pairs checked by hand against the urbit-ob documentation and
+// Hoon's +scot, so unlike goldenFile it doesn't come from this implementation.
+const knownFile string = "known.json"
+
+func TestCheckKnown(t *testing.T) {
+
+	f, err := os.Open(filepath.Join("testdata", knownFile))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	file, err := Read(f)
+	if assert.NoError(t, err) {
+		assert.Len(t, file.Vectors, 7)
+		assert.Empty(t, Check(file))
+	}
+}
+
+func TestCheckMismatches(t *testing.T) {
+
+	file, err := Read(strings.NewReader(`{"version": 1, "seed": 0, "vectors": [
+		{"number":"1624961343","patp":"~sampel-palnet","patq":"~ronler-talpur","hex":"60daf13f","clan":"planet","sein":"~talpur"},
+		{"number":"256","patp":"~marnec","patq":"~marzod","hex":"0100","clan":"star","sein":"~zod"},
+		{"number":"-1"}
+	]}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var mismatches []string
+	for _, m := range Check(file) {
+		mismatches = append(mismatches, m.String())
+	}
+
+	assert.Equal(t, []string{
+		`vector 1 (256): patp is "~marzod", expected "~marnec"`,
+		`vector 1 (256): patp2dec is "257", expected "256"`,
+		`vector 2 (-1): number is "invalid integer string: -1", expected "-1"`,
+	}, mismatches)
+}
+
+func TestReadVersion(t *testing.T) {
+
+	_, err := Read(strings.NewReader(`{"version": 2}`))
+	assert.EqualError(t, err, "unsupported vector file version: 2")
+}
@@ -2,4 +2,7 @@ module github.com/deelawn/urbit-gob
 
 go 1.15
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/term v0.0.0-20201210144234-2321bbc49cbf
+)
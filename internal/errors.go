@@ -2,9 +2,31 @@ package internal
 
 const (
 	// Error format strings
-	ErrInvalidBin string = "invalid binary string: %s"
-	ErrInvalidHex string = "invalid hexadecimal string: %s"
-	ErrInvalidInt string = "invalid integer string: %s"
-	ErrInvalidP   string = "invalid @p: %s"
-	ErrInvalidQ   string = "invalid @q: %s"
+	ErrAuraPanic        string = "aura codec panicked: %v"
+	ErrCipherDomain     string = "value %s is outside of the cipher domain [0, %s)"
+	ErrCueBackref       string = "invalid backreference at bit %d: %d"
+	ErrCueLimit         string = "cue limit exceeded: more than %d %s"
+	ErrCueTruncated     string = "truncated jam at bit %d"
+	ErrInvalidAtom      string = "invalid @%s: %s"
+	ErrInvalidAura      string = "invalid aura name: %s"
+	ErrInvalidBin       string = "invalid binary string: %s"
+	ErrInvalidCipher    string = "invalid cipher: %s"
+	ErrInvalidHex       string = "invalid hexadecimal string: %s"
+	ErrInvalidInt       string = "invalid integer string: %s"
+	ErrInvalidP         string = "invalid @p: %s"
+	ErrInvalidQ         string = "invalid @q: %s"
+	ErrNilAuraCodec     string = "nil codec for aura: @%s"
+	ErrNounSyntax       string = "invalid noun at offset %d: %s"
+	ErrPatRange         string = "@%s out of range for 64 bits: %s"
+	ErrShipClass        string = "%s is a %s, but it must be a %s"
+	ErrShipScan         string = "cannot scan %T into a ship"
+	ErrShipSponsor      string = "%s is not descended from %s: %s"
+	ErrUnknownAura      string = "unknown aura: @%s"
+	ErrUnknownLiteral   string = "unrecognized atom literal: %s"
+	ErrUnknownShipClass string = "unknown ship class: %s"
+	ErrUrbauthAmbiguous string = "urbauth cookies for more than one ship: %s and %s"
+	ErrUrbauthCookie    string = "invalid urbauth cookie name: %s"
+	ErrUrbauthMissing   string = "no urbauth cookie"
+	ErrVerifyRange      string = "invalid range [%d, %d]: start is after end"
+	ErrVectorVersion    string = "unsupported vector file version: %d"
 )
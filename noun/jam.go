@@ -0,0 +1,416 @@
+package noun
+
+import (
+	"fmt"
+	"math/big"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// CueLimits bounds the work Cue will do on untrusted input. A zero field
+// means no limit.
+type CueLimits struct {
+	// MaxBits is the largest jammed atom, in bits, that will be decoded.
+	MaxBits int
+	// MaxDepth is the deepest nesting of cells that will be decoded.
+	MaxDepth int
+	// MaxNouns is the largest number of nouns the result may contain once
+	// backreferences are expanded. Backreferences let a small input describe
+	// an exponentially large tree, which anything that walks the result
+	// would otherwise have to deal with.
+	MaxNouns uint64
+}
+
+// DefaultCueLimits are the limits used by Cue.
+var DefaultCueLimits = CueLimits{
+	MaxBits:  1 << 32,
+	MaxDepth: 1 << 20,
+	MaxNouns: 1 << 26,
+}
+
+// Jam serializes a noun into an atom, bit for bit as Hoon's jam does.
+// Repeated subnouns are written as backreferences.
+func Jam(n Noun) *big.Int {
+
+	ids := newInterner()
+	seen := map[int]int{}
+	w := &bitWriter{}
+
+	stack := []Noun{n}
+	for len(stack) > 0 {
+
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		id := ids.id(n)
+		if at, ok := seen[id]; ok {
+			ref := big.NewInt(int64(at))
+			if a, ok := n.(Atom); ok && a.BitLen() <= ref.BitLen() {
+				w.writeBit(0)
+				w.mat(a.bits())
+				continue
+			}
+			w.writeBits(3, 2)
+			w.mat(ref)
+			continue
+		}
+
+		seen[id] = w.n
+
+		switch n := n.(type) {
+		case Atom:
+			w.writeBit(0)
+			w.mat(n.bits())
+		case *Cell:
+			w.writeBits(1, 2)
+			stack = append(stack, n.Tail, n.Head)
+		}
+	}
+
+	return w.atom()
+}
+
+// Cue deserializes an atom produced by Jam, using DefaultCueLimits.
+func Cue(a *big.Int) (Noun, error) {
+
+	return CueWithLimits(a, DefaultCueLimits)
+}
+
+// CueWithLimits deserializes an atom produced by Jam, failing if the input
+// exceeds any of the given limits.
+func CueWithLimits(a *big.Int, limits CueLimits) (Noun, error) {
+
+	if a == nil || a.Sign() <= 0 {
+		return nil, fmt.Errorf(ugi.ErrCueTruncated, 0)
+	}
+
+	if limits.MaxBits > 0 && a.BitLen() > limits.MaxBits {
+		return nil, fmt.Errorf(ugi.ErrCueLimit, limits.MaxBits, "bits")
+	}
+
+	type frame struct {
+		at   int
+		head Noun
+		size uint64
+		full bool
+	}
+
+	type entry struct {
+		noun Noun
+		size uint64
+	}
+
+	r := newBitReader(a)
+	memo := map[int]entry{}
+	var stack []frame
+
+	for {
+
+		at := r.pos
+		var (
+			n    Noun
+			size uint64
+		)
+
+		tag, err := r.readBit()
+		if err != nil {
+			return nil, err
+		}
+
+		if tag == 0 {
+			v, err := r.rub()
+			if err != nil {
+				return nil, err
+			}
+			n, size = NewAtom(v), 1
+			memo[at] = entry{n, size}
+		} else {
+			if tag, err = r.readBit(); err != nil {
+				return nil, err
+			}
+			if tag == 0 {
+				if limits.MaxDepth > 0 && len(stack) >= limits.MaxDepth {
+					return nil, fmt.Errorf(ugi.ErrCueLimit, limits.MaxDepth, "levels of nesting")
+				}
+				stack = append(stack, frame{at: at})
+				continue
+			}
+			ref, err := r.rub()
+			if err != nil {
+				return nil, err
+			}
+			if !ref.IsInt64() {
+				return nil, fmt.Errorf(ugi.ErrCueBackref, at, ref)
+			}
+			e, ok := memo[int(ref.Int64())]
+			if !ok {
+				return nil, fmt.Errorf(ugi.ErrCueBackref, at, ref)
+			}
+			n, size = e.noun, e.size
+		}
+
+		// Attach the finished noun to the cells waiting on it.
+		for {
+			if limits.MaxNouns > 0 && size > limits.MaxNouns {
+				return nil, fmt.Errorf(ugi.ErrCueLimit, limits.MaxNouns, "nouns")
+			}
+
+			if len(stack) == 0 {
+				return n, nil
+			}
+
+			top := &stack[len(stack)-1]
+			if !top.full {
+				top.head, top.size, top.full = n, size, true
+				break
+			}
+
+			n, size = &Cell{Head: top.head, Tail: n}, 1+top.size+size
+			memo[top.at] = entry{n, size}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// interner assigns the same id to equal nouns, so that Jam can find repeated
+// subnouns without comparing them.
+type interner struct {
+	atoms map[string]int
+	cells map[[2]int]int
+	memo  map[*Cell]int
+}
+
+func newInterner() *interner {
+
+	return &interner{
+		atoms: map[string]int{},
+		cells: map[[2]int]int{},
+		memo:  map[*Cell]int{},
+	}
+}
+
+func (in *interner) id(n Noun) int {
+
+	if a, ok := n.(Atom); ok {
+		return in.atom(a)
+	}
+
+	root := n.(*Cell)
+	if id, ok := in.memo[root]; ok {
+		return id
+	}
+
+	// Walk the cell in post-order so that children have ids before their
+	// parents.
+	stack := []*Cell{root}
+	for len(stack) > 0 {
+
+		c := stack[len(stack)-1]
+		if _, ok := in.memo[c]; ok {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		head, headOk := in.known(c.Head)
+		tail, tailOk := in.known(c.Tail)
+		if !headOk {
+			stack = append(stack, c.Head.(*Cell))
+		}
+		if !tailOk {
+			stack = append(stack, c.Tail.(*Cell))
+		}
+		if !headOk || !tailOk {
+			continue
+		}
+
+		key := [2]int{head, tail}
+		id, ok := in.cells[key]
+		if !ok {
+			id = len(in.atoms) + len(in.cells)
+			in.cells[key] = id
+		}
+		in.memo[c] = id
+		stack = stack[:len(stack)-1]
+	}
+
+	return in.memo[root]
+}
+
+func (in *interner) known(n Noun) (int, bool) {
+
+	if a, ok := n.(Atom); ok {
+		return in.atom(a), true
+	}
+
+	id, ok := in.memo[n.(*Cell)]
+	return id, ok
+}
+
+func (in *interner) atom(a Atom) int {
+
+	key := string(a.bits().Bytes())
+	id, ok := in.atoms[key]
+	if !ok {
+		id = len(in.atoms) + len(in.cells)
+		in.atoms[key] = id
+	}
+
+	return id
+}
+
+// bitWriter accumulates bits least significant first.
+type bitWriter struct {
+	buf []byte
+	n   int
+}
+
+func (w *bitWriter) writeBit(b uint) {
+
+	if w.n%8 == 0 {
+		w.buf = append(w.buf, 0)
+	}
+
+	w.buf[w.n/8] |= byte(b&1) << uint(w.n%8)
+	w.n++
+}
+
+// writeBits writes the low n bits of v.
+func (w *bitWriter) writeBits(v uint64, n int) {
+
+	for i := 0; i < n; i++ {
+		w.writeBit(uint(v >> uint(i)))
+	}
+}
+
+// writeAtom writes all the bits of a.
+func (w *bitWriter) writeAtom(a *big.Int) {
+
+	buf := a.Bytes()
+	n := a.BitLen()
+	for i := len(buf) - 1; i >= 0; i-- {
+		bits := 8
+		if i == 0 {
+			bits = n - 8*(len(buf)-1)
+		}
+		w.writeBits(uint64(buf[i]), bits)
+	}
+}
+
+// mat writes the self-delimiting length-prefixed encoding of an atom.
+func (w *bitWriter) mat(a *big.Int) {
+
+	if a.Sign() == 0 {
+		w.writeBit(1)
+		return
+	}
+
+	b := a.BitLen()
+	c := big.NewInt(int64(b)).BitLen()
+
+	w.writeBits(0, c)
+	w.writeBit(1)
+	w.writeBits(uint64(b), c-1)
+	w.writeAtom(a)
+}
+
+func (w *bitWriter) atom() *big.Int {
+
+	buf := make([]byte, len(w.buf))
+	for i, c := range w.buf {
+		buf[len(buf)-1-i] = c
+	}
+
+	return big.NewInt(0).SetBytes(buf)
+}
+
+// bitReader reads the bits of an atom least significant first.
+type bitReader struct {
+	buf []byte
+	len int
+	pos int
+}
+
+func newBitReader(a *big.Int) *bitReader {
+
+	buf := a.Bytes()
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	return &bitReader{buf: buf, len: a.BitLen()}
+}
+
+func (r *bitReader) readBit() (uint, error) {
+
+	if r.pos >= r.len {
+		return 0, fmt.Errorf(ugi.ErrCueTruncated, r.pos)
+	}
+
+	b := uint(r.buf[r.pos/8]>>uint(r.pos%8)) & 1
+	r.pos++
+	return b, nil
+}
+
+// readAtom reads an n-bit atom.
+func (r *bitReader) readAtom(n int) (*big.Int, error) {
+
+	if n > r.len-r.pos {
+		return nil, fmt.Errorf(ugi.ErrCueTruncated, r.pos)
+	}
+
+	// Gather the bits into big-endian bytes for SetBytes.
+	out := make([]byte, (n+7)/8)
+	shift := uint(r.pos % 8)
+	for k := range out {
+		i := r.pos/8 + k
+		v := r.buf[i] >> shift
+		if shift > 0 && i+1 < len(r.buf) {
+			v |= r.buf[i+1] << (8 - shift)
+		}
+		if rem := n - 8*k; rem < 8 {
+			v &= byte(1)<<uint(rem) - 1
+		}
+		out[len(out)-1-k] = v
+	}
+
+	r.pos += n
+	return big.NewInt(0).SetBytes(out), nil
+}
+
+// rub reads an atom written by mat.
+func (r *bitReader) rub() (*big.Int, error) {
+
+	c := 0
+	for {
+		b, err := r.readBit()
+		if err != nil {
+			return nil, err
+		}
+		if b == 1 {
+			break
+		}
+		c++
+	}
+
+	if c == 0 {
+		return big.NewInt(0), nil
+	}
+
+	// The length of the atom takes c bits, the top one implicit, and can't
+	// be longer than what is left of the input.
+	if c > 63 {
+		return nil, fmt.Errorf(ugi.ErrCueTruncated, r.pos)
+	}
+
+	low, err := r.readAtom(c - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	b := int64(1)<<uint(c-1) | low.Int64()
+	if b > int64(r.len-r.pos) {
+		return nil, fmt.Errorf(ugi.ErrCueTruncated, r.pos)
+	}
+
+	return r.readAtom(int(b))
+}
@@ -0,0 +1,201 @@
+package noun
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func a(v uint64) Atom {
+
+	return AtomFromUint64(v)
+}
+
+// hoonJam is a direct transcription of Hoon's jam, used as a reference.
+func hoonJam(n Noun) *big.Int {
+
+	type seen struct {
+		noun Noun
+		at   *big.Int
+	}
+
+	var (
+		m   []seen
+		jam func(n Noun, b *big.Int) (*big.Int, *big.Int)
+	)
+
+	mat := func(a *big.Int) (*big.Int, *big.Int) {
+		if a.Sign() == 0 {
+			return big.NewInt(1), big.NewInt(1)
+		}
+		b := big.NewInt(int64(a.BitLen()))
+		c := int64(b.BitLen())
+		p := big.NewInt(2*c + b.Int64())
+		low := big.NewInt(0).And(b, big.NewInt(1<<uint(c-1)-1))
+		q := big.NewInt(0).Or(low, big.NewInt(0).Lsh(a, uint(c-1)))
+		q.Lsh(q, uint(c+1))
+		q.Or(q, big.NewInt(1<<uint(c)))
+		return p, q
+	}
+
+	jam = func(n Noun, b *big.Int) (*big.Int, *big.Int) {
+		for _, s := range m {
+			if !Equal(s.noun, n) {
+				continue
+			}
+			if x, ok := n.(Atom); ok && x.BitLen() <= s.at.BitLen() {
+				p, q := mat(x.Big())
+				return p.Add(p, big.NewInt(1)), q.Lsh(q, 1)
+			}
+			p, q := mat(s.at)
+			return p.Add(p, big.NewInt(2)), q.Or(q.Lsh(q, 2), big.NewInt(3))
+		}
+		m = append(m, seen{n, b})
+		if x, ok := n.(Atom); ok {
+			p, q := mat(x.Big())
+			return p.Add(p, big.NewInt(1)), q.Lsh(q, 1)
+		}
+		c := n.(*Cell)
+		b = big.NewInt(0).Add(b, big.NewInt(2))
+		pd, qd := jam(c.Head, b)
+		pe, qe := jam(c.Tail, big.NewInt(0).Add(b, pd))
+		q := big.NewInt(0).Or(qd, big.NewInt(0).Lsh(qe, uint(pd.Int64())))
+		q.Lsh(q, 2)
+		return big.NewInt(0).Add(big.NewInt(2), big.NewInt(0).Add(pd, pe)), q.Or(q, big.NewInt(1))
+	}
+
+	_, q := jam(n, big.NewInt(0))
+	return q
+}
+
+func randomNoun(r *rand.Rand, depth int, pool *[]Noun) Noun {
+
+	if len(*pool) > 0 && r.Intn(4) == 0 {
+		return (*pool)[r.Intn(len(*pool))]
+	}
+
+	var n Noun
+	if depth == 0 || r.Intn(3) == 0 {
+		n = a(uint64(r.Int63n(1 << uint(r.Intn(62)+1))))
+	} else {
+		n = NewCell(randomNoun(r, depth-1, pool), randomNoun(r, depth-1, pool))
+	}
+
+	*pool = append(*pool, n)
+	return n
+}
+
+func TestJam(t *testing.T) {
+
+	var testCases = []struct {
+		name string
+		in   Noun
+		out  string
+	}{
+		{name: "0", in: a(0), out: "2"},
+		{name: "1", in: a(1), out: "12"},
+		{name: "2", in: a(2), out: "72"},
+		{name: "[0 0]", in: NewCell(a(0), a(0)), out: "41"},
+		{name: "[1 2]", in: NewCell(a(1), a(2)), out: "4657"},
+		{name: "[[1 2] [1 2]]", in: NewCell(NewCell(a(1), a(2)), NewCell(a(1), a(2))), out: "4835525"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			jammed := Jam(tt.in)
+			assert.Equal(t, tt.out, jammed.String())
+			assert.Equal(t, hoonJam(tt.in).String(), jammed.String())
+
+			cued, err := Cue(jammed)
+			if assert.NoError(t, err) {
+				assert.True(t, Equal(tt.in, cued))
+			}
+		})
+	}
+}
+
+func TestJamMatchesHoon(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+
+		var pool []Noun
+		n := randomNoun(r, 6, &pool)
+
+		jammed := Jam(n)
+		if !assert.Equal(t, hoonJam(n).String(), jammed.String()) {
+			return
+		}
+
+		cued, err := Cue(jammed)
+		if assert.NoError(t, err) {
+			assert.True(t, Equal(n, cued))
+		}
+	}
+}
+
+func TestCueDeepList(t *testing.T) {
+
+	var n Noun = a(0)
+	for i := 0; i < 100000; i++ {
+		n = NewCell(a(uint64(i)), n)
+	}
+
+	cued, err := Cue(Jam(n))
+	if assert.NoError(t, err) {
+		assert.True(t, Equal(n, cued))
+	}
+}
+
+func TestCueInvalid(t *testing.T) {
+
+	var testCases = []struct {
+		name string
+		in   *big.Int
+		err  string
+	}{
+		{name: "zero", in: big.NewInt(0), err: "truncated jam at bit 0"},
+		{name: "cell without tail", in: big.NewInt(0x9), err: "truncated jam at bit 4"},
+		{name: "forward backreference", in: big.NewInt(0x1f), err: "invalid backreference at bit 0: 0"},
+		{name: "oversized length", in: big.NewInt(0x10), err: "truncated jam at bit 5"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			_, err := Cue(tt.in)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCueLimits(t *testing.T) {
+
+	// Each level refers back to the previous one twice, so the expanded
+	// tree doubles in size with every level.
+	var n Noun = a(1)
+	for i := 0; i < 40; i++ {
+		n = NewCell(n, n)
+	}
+
+	jammed := Jam(n)
+	assert.Less(t, jammed.BitLen(), 2000)
+
+	_, err := Cue(jammed)
+	assert.EqualError(t, err, "cue limit exceeded: more than 67108864 nouns")
+
+	_, err = CueWithLimits(jammed, CueLimits{MaxBits: 100})
+	assert.EqualError(t, err, "cue limit exceeded: more than 100 bits")
+
+	_, err = CueWithLimits(jammed, CueLimits{MaxDepth: 10})
+	assert.EqualError(t, err, "cue limit exceeded: more than 10 levels of nesting")
+
+	cued, err := CueWithLimits(jammed, CueLimits{})
+	if assert.NoError(t, err) {
+		assert.True(t, Equal(n, cued))
+	}
+}
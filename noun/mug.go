@@ -0,0 +1,94 @@
+package noun
+
+import (
+	"math/big"
+
+	"github.com/deelawn/urbit-gob/ob"
+)
+
+const (
+	mugAtomSeed uint32 = 0xcafebabe
+	mugAtomFail uint32 = 0x7fff
+	mugCellSeed uint32 = 0xdeadbeef
+	mugCellFail uint32 = 0xfffe
+	mugRetries  int    = 8
+)
+
+// Mug computes Urbit's 31-bit noun hash, as used by Arvo's maps and sets.
+func Mug(n Noun) uint32 {
+
+	if a, ok := n.(Atom); ok {
+		return mugAtom(a.bits())
+	}
+
+	root := n.(*Cell)
+	memo := map[*Cell]uint32{}
+
+	// Walk the cell in post-order so that children are hashed before their
+	// parents, hashing each shared subnoun only once.
+	stack := []*Cell{root}
+	for len(stack) > 0 {
+
+		c := stack[len(stack)-1]
+		if _, ok := memo[c]; ok {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		head, headOk := knownMug(c.Head, memo)
+		tail, tailOk := knownMug(c.Tail, memo)
+		if !headOk {
+			stack = append(stack, c.Head.(*Cell))
+		}
+		if !tailOk {
+			stack = append(stack, c.Tail.(*Cell))
+		}
+		if !headOk || !tailOk {
+			continue
+		}
+
+		memo[c] = mugBoth(head, tail)
+		stack = stack[:len(stack)-1]
+	}
+
+	return memo[root]
+}
+
+func knownMug(n Noun, memo map[*Cell]uint32) (uint32, bool) {
+
+	if a, ok := n.(Atom); ok {
+		return mugAtom(a.bits()), true
+	}
+
+	h, ok := memo[n.(*Cell)]
+	return h, ok
+}
+
+func mugAtom(a *big.Int) uint32 {
+
+	return mum(mugAtomSeed, mugAtomFail, a)
+}
+
+// mugBoth hashes a cell from the mugs of its head and tail.
+func mugBoth(head, tail uint32) uint32 {
+
+	key := big.NewInt(0).SetUint64(uint64(tail)<<32 | uint64(head))
+	return mum(mugCellSeed, mugCellFail, key)
+}
+
+// mum is Hoon's +mum: it hashes the key with successive seeds until the hash
+// folded to 31 bits is non-zero, giving up with fal after eight tries.
+func mum(syd, fal uint32, key *big.Int) uint32 {
+
+	length := (key.BitLen() + 7) / 8
+	for i := 0; i < mugRetries; i++ {
+		haz := ob.Muk(syd, length, key)
+		ham := (haz >> 31) ^ (haz & 0x7fffffff)
+		if ham != 0 {
+			return ham
+		}
+		syd++
+	}
+
+	return fal
+}
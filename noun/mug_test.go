@@ -0,0 +1,51 @@
+package noun
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMug(t *testing.T) {
+
+	var testCases = []struct {
+		name string
+		in   Noun
+		out  uint32
+	}{
+		{name: "0", in: a(0), out: 0x79ff04e8},
+		{name: "1", in: a(1), out: 0x715c2a60},
+		{name: "%foo", in: a(7303014), out: 1772934686},
+		{name: "[0 0]", in: NewCell(a(0), a(0)), out: 422532488},
+		{name: "[1 2]", in: NewCell(a(1), a(2)), out: 1781973465},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			assert.Equal(t, tt.out, Mug(tt.in))
+		})
+	}
+}
+
+func TestMugIsStructural(t *testing.T) {
+
+	shared := NewCell(a(1), a(2))
+	n := NewCell(shared, shared)
+	m := NewCell(NewCell(a(1), a(2)), NewCell(a(1), a(2)))
+
+	assert.Equal(t, Mug(m), Mug(n))
+	assert.NotEqual(t, Mug(NewCell(a(2), a(1))), Mug(shared))
+	assert.Equal(t, Mug(a(1<<40)), Mug(NewAtom(big.NewInt(1<<40))))
+}
+
+func TestMugFitsIn31Bits(t *testing.T) {
+
+	var n Noun = a(0)
+	for i := uint64(0); i < 1000; i++ {
+		n = NewCell(a(i), n)
+		assert.NotZero(t, Mug(n))
+		assert.Less(t, Mug(n), uint32(1<<31))
+	}
+}
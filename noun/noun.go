@@ -0,0 +1,138 @@
+// Package noun implements Urbit nouns along with the jam and cue
+// serialization used for everything Urbit sends over the wire or writes to
+// disk.
+package noun
+
+import (
+	"math/big"
+)
+
+// Noun is either an Atom or a *Cell.
+type Noun interface {
+	isNoun()
+}
+
+// Atom is a natural number. The zero value is the atom 0.
+type Atom struct {
+	v *big.Int
+}
+
+// Cell is an ordered pair of nouns.
+type Cell struct {
+	Head Noun
+	Tail Noun
+}
+
+func (Atom) isNoun() {}
+
+func (*Cell) isNoun() {}
+
+// NewAtom returns an atom with the value of v, which must not be negative.
+func NewAtom(v *big.Int) Atom {
+
+	if v == nil || v.Sign() == 0 {
+		return Atom{}
+	}
+
+	return Atom{v: big.NewInt(0).Abs(v)}
+}
+
+// AtomFromUint64 returns an atom with the value of v.
+func AtomFromUint64(v uint64) Atom {
+
+	if v == 0 {
+		return Atom{}
+	}
+
+	return Atom{v: big.NewInt(0).SetUint64(v)}
+}
+
+// Big returns the value of the atom.
+func (a Atom) Big() *big.Int {
+
+	if a.v == nil {
+		return big.NewInt(0)
+	}
+
+	return big.NewInt(0).Set(a.v)
+}
+
+// IsZero reports whether the atom is 0.
+func (a Atom) IsZero() bool {
+
+	return a.v == nil || a.v.Sign() == 0
+}
+
+// BitLen returns the number of bits needed to represent the atom, which is
+// Hoon's (met 0 a).
+func (a Atom) BitLen() int {
+
+	if a.v == nil {
+		return 0
+	}
+
+	return a.v.BitLen()
+}
+
+// bits returns the value of the atom without copying it; it must not be
+// modified.
+func (a Atom) bits() *big.Int {
+
+	if a.v == nil {
+		return zero
+	}
+
+	return a.v
+}
+
+var zero = big.NewInt(0)
+
+// NewCell returns the cell [head tail]. Additional nouns build a
+// right-nested tuple, so NewCell(a, b, c) is [a [b c]].
+func NewCell(head, tail Noun, more ...Noun) *Cell {
+
+	if len(more) == 0 {
+		return &Cell{Head: head, Tail: tail}
+	}
+
+	return &Cell{Head: head, Tail: NewCell(tail, more[0], more[1:]...)}
+}
+
+// Equal reports whether two nouns have the same structure and values.
+func Equal(a, b Noun) bool {
+
+	type pair struct{ a, b Noun }
+	stack := []pair{{a, b}}
+
+	// Nouns from Cue share subnouns, so remember which pairs of cells have
+	// been compared to avoid walking the same pair twice.
+	compared := map[[2]*Cell]bool{}
+
+	for len(stack) > 0 {
+
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch x := p.a.(type) {
+		case Atom:
+			y, ok := p.b.(Atom)
+			if !ok || x.bits().Cmp(y.bits()) != 0 {
+				return false
+			}
+		case *Cell:
+			y, ok := p.b.(*Cell)
+			if !ok {
+				return false
+			}
+			if x == y || compared[[2]*Cell{x, y}] {
+				continue
+			}
+			compared[[2]*Cell{x, y}] = true
+			stack = append(stack, pair{x.Tail, y.Tail}, pair{x.Head, y.Head})
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+package noun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// Format renders a noun in Hoon syntax with every atom rendered under the
+// given aura, e.g. Format(n, "p") gives [~zod ~nec]. Right-nested cells are
+// flattened into tuples the way the Dojo prints them, so [1 [2 3]] is
+// rendered as [1 2 3].
+func Format(n Noun, aura string) string {
+
+	var b strings.Builder
+	format(&b, n, aura)
+	return b.String()
+}
+
+func format(b *strings.Builder, n Noun, aura string) {
+
+	switch n := n.(type) {
+	case Atom:
+		b.WriteString(co.Scot(aura, n.bits()))
+	case *Cell:
+		b.WriteByte('[')
+		format(b, n.Head, aura)
+		tail := n.Tail
+		for {
+			b.WriteByte(' ')
+			c, ok := tail.(*Cell)
+			if !ok {
+				format(b, tail, aura)
+				break
+			}
+			format(b, c.Head, aura)
+			tail = c.Tail
+		}
+		b.WriteByte(']')
+	}
+}
+
+// String renders the atom as @ud.
+func (a Atom) String() string {
+
+	return Format(a, "ud")
+}
+
+// String renders the cell with its atoms as @ud.
+func (c *Cell) String() string {
+
+	return Format(c, "ud")
+}
+
+/*
+Parse reads a noun written in Hoon syntax, such as [1 ~zod [0x1f %foo]].
+
+Atoms may be written in any aura co.Nuck recognizes, and tuples of more than
+two nouns are right-nested, so [1 2 3] is [1 [2 3]]. Any amount of whitespace
+may separate nouns.
+*/
+func Parse(text string) (Noun, error) {
+
+	p := &parser{text: text}
+	p.skipSpace()
+
+	n, err := p.noun()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unexpected %q", p.text[p.pos])
+	}
+
+	return n, nil
+}
+
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+
+	return fmt.Errorf(ugi.ErrNounSyntax, p.pos, fmt.Sprintf(format, args...))
+}
+
+func isSpace(c byte) bool {
+
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *parser) skipSpace() {
+
+	for p.pos < len(p.text) && isSpace(p.text[p.pos]) {
+		p.pos++
+	}
+}
+
+// noun reads a noun without recursing, so that deeply nested input can't
+// exhaust the stack.
+func (p *parser) noun() (Noun, error) {
+
+	// stack holds the nouns read so far in each open tuple, innermost last.
+	var stack [][]Noun
+	for {
+		if len(stack) > 0 {
+			p.skipSpace()
+		}
+		if p.pos >= len(p.text) {
+			if len(stack) > 0 {
+				return nil, p.errorf("unclosed [")
+			}
+			return nil, p.errorf("unexpected end of input")
+		}
+
+		var n Noun
+		c := p.text[p.pos]
+		if c == ']' && len(stack) > 0 {
+			items := stack[len(stack)-1]
+			if len(items) < 2 {
+				return nil, p.errorf("a cell needs at least two nouns")
+			}
+			p.pos++
+			stack = stack[:len(stack)-1]
+			n = NewCell(items[0], items[1], items[2:]...)
+		} else {
+			if len(stack) > 0 && len(stack[len(stack)-1]) > 0 && !isSpace(p.text[p.pos-1]) {
+				return nil, p.errorf("missing space before %q", c)
+			}
+			if c == '[' {
+				p.pos++
+				stack = append(stack, nil)
+				continue
+			}
+			atom, err := p.atom()
+			if err != nil {
+				return nil, err
+			}
+			n = atom
+		}
+
+		if len(stack) == 0 {
+			return n, nil
+		}
+		stack[len(stack)-1] = append(stack[len(stack)-1], n)
+	}
+}
+
+func (p *parser) atom() (Noun, error) {
+
+	start := p.pos
+	for p.pos < len(p.text) && !isSpace(p.text[p.pos]) && p.text[p.pos] != '[' && p.text[p.pos] != ']' {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return nil, p.errorf("unexpected %q", p.text[p.pos])
+	}
+
+	lit, err := co.Nuck(p.text[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("%s", err)
+	}
+
+	return NewAtom(lit.Atom), nil
+}
@@ -0,0 +1,111 @@
+package noun
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+
+	var testCases = []struct {
+		in   Noun
+		aura string
+		out  string
+	}{
+		{in: a(1024), aura: "ud", out: "1.024"},
+		{in: NewCell(a(1), a(2)), aura: "ud", out: "[1 2]"},
+		{in: NewCell(a(1), a(2), NewCell(a(3), a(4))), aura: "ud", out: "[1 2 3 4]"},
+		{in: NewCell(NewCell(a(1), a(2)), a(3)), aura: "ud", out: "[[1 2] 3]"},
+		{in: NewCell(a(1), NewCell(NewCell(a(2), a(3)), a(4))), aura: "ud", out: "[1 [2 3] 4]"},
+		{in: NewCell(a(0), a(256), a(65536)), aura: "p", out: "[~zod ~marzod ~dapnep-ronmyl]"},
+		{in: NewCell(a(31), a(65536)), aura: "ux", out: "[0x1f 0x1.0000]"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.out, func(t *testing.T) {
+
+			assert.Equal(t, tt.out, Format(tt.in, tt.aura))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	var testCases = []struct {
+		in  string
+		out Noun
+	}{
+		{in: "1.024", out: a(1024)},
+		{in: "[1 2]", out: NewCell(a(1), a(2))},
+		{in: "[1 2 [3 4]]", out: NewCell(a(1), a(2), a(3), a(4))},
+		{in: "[[1 2] 3]", out: NewCell(NewCell(a(1), a(2)), a(3))},
+		{in: " [~zod\n  0x1f  %foo] ", out: NewCell(a(0), a(31), a(7303014))},
+		{in: "[~ ~marzod]", out: NewCell(a(0), a(256))},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			n, err := Parse(tt.in)
+			if assert.NoError(t, err) {
+				assert.True(t, Equal(tt.out, n), "got %s", n)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+
+	var testCases = []struct {
+		in  string
+		err string
+	}{
+		{in: "", err: "invalid noun at offset 0: unexpected end of input"},
+		{in: "[1 2", err: "invalid noun at offset 4: unclosed ["},
+		{in: "[1]", err: "invalid noun at offset 2: a cell needs at least two nouns"},
+		{in: "[1 2]]", err: "invalid noun at offset 5: unexpected ']'"},
+		{in: "[1 2[3 4]]", err: "invalid noun at offset 4: missing space before '['"},
+		{in: "[1 1024]", err: "invalid noun at offset 3: unrecognized atom literal: 1024"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			_, err := Parse(tt.in)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseDeep(t *testing.T) {
+
+	_, err := Parse(strings.Repeat("[", 1<<20))
+	assert.EqualError(t, err, "invalid noun at offset 1048576: unclosed [")
+
+	const depth = 1 << 16
+	n, err := Parse(strings.Repeat("[", depth) + "1" + strings.Repeat(" 2]", depth))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i := 0; i < depth; i++ {
+		c, ok := n.(*Cell)
+		if !assert.True(t, ok) {
+			return
+		}
+		n = c.Head
+	}
+	assert.True(t, Equal(a(1), n))
+}
+
+func TestParseFormatRoundTrip(t *testing.T) {
+
+	n := NewCell(a(1), NewCell(NewCell(a(2), a(3)), a(4)), a(1<<40))
+	for _, aura := range []string{"ud", "ux", "p", "q", "uv"} {
+		parsed, err := Parse(Format(n, aura))
+		if assert.NoError(t, err, aura) {
+			assert.True(t, Equal(n, parsed), aura)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package ob
+
+import (
+	"fmt"
+	"math/big"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const defaultRounds int = 4
+
+/*
+Cipher is the Feistel cipher Urbit uses to scramble ship names, generalized
+so that it can permute any range of integers.
+
+The cipher permutes [0, Domain). Each value is split into a pair of digits
+with bases A and B, A <= B, which are scrambled over Rounds rounds using the
+murmur3 hash seeded with Keys. When Domain is smaller than A*B, results that
+fall outside of it are encrypted again until they fall inside, which is known
+as cycle walking.
+
+The zero value of each field selects Urbit's parameters: the four raku keys,
+four rounds and a split of 65535 by 65536. If only Domain is set, A and B
+default to the smallest square split that covers it instead.
+*/
+type Cipher struct {
+	// Keys seed the round function; round j uses Keys[j%len(Keys)].
+	Keys []uint32
+	// Rounds is the number of Feistel rounds.
+	Rounds int
+	// A and B are the bases the domain is split into, with A <= B.
+	A *big.Int
+	B *big.Int
+	// Domain is the number of values permuted, at most A*B.
+	Domain *big.Int
+}
+
+// cipher is a Cipher with its defaults filled in and checked.
+type cipher struct {
+	keys   []uint32
+	rounds int
+	a      *big.Int
+	b      *big.Int
+	domain *big.Int
+	// keyLen is the number of bytes of each half that the round function
+	// hashes. Hoon always hashes 2, while a Cipher hashes enough for any
+	// digit in base A or B.
+	keyLen int
+	// generalized is set for a Cipher, whose halves are combined so that any
+	// A <= B and any number of rounds give a permutation. Otherwise they are
+	// combined exactly as Hoon's fe and fen combine them.
+	generalized bool
+}
+
+// newCipher returns the cipher of Hoon's fe and fen, which Fe and Fen give
+// the same results as for every input.
+func newCipher(keys []uint32, rounds int, a, b *big.Int) *cipher {
+
+	return &cipher{
+		keys:   keys,
+		rounds: rounds,
+		a:      a,
+		b:      b,
+		keyLen: 2,
+	}
+}
+
+func (c *Cipher) build() (*cipher, error) {
+
+	keys, rounds, a, b := c.Keys, c.Rounds, c.A, c.B
+	if keys == nil {
+		keys = raku
+	}
+	if rounds == 0 {
+		rounds = defaultRounds
+	}
+	if a == nil && b == nil && c.Domain != nil && c.Domain.Sign() > 0 {
+		// Split a custom domain as evenly as possible, so that cycle walking
+		// rarely needs more than one pass.
+		a = big.NewInt(0).Sqrt(big.NewInt(0).Sub(c.Domain, big.NewInt(1)))
+		a.Add(a, big.NewInt(1))
+		b = a
+	}
+	if a == nil {
+		a = u65535
+	}
+	if b == nil {
+		b = u65536
+	}
+
+	if len(keys) == 0 || rounds < 0 || a.Sign() <= 0 || b.Sign() <= 0 {
+		return nil, fmt.Errorf(ugi.ErrInvalidCipher, "keys, rounds and bases must be positive")
+	}
+
+	if a.Cmp(b) > 0 {
+		return nil, fmt.Errorf(ugi.ErrInvalidCipher, "A must not be greater than B")
+	}
+
+	built := newCipher(keys, rounds, a, b)
+	built.generalized = true
+	built.keyLen = (big.NewInt(0).Sub(b, big.NewInt(1)).BitLen() + 7) / 8
+
+	built.domain = big.NewInt(0).Mul(a, b)
+	if c.Domain != nil {
+		if c.Domain.Sign() <= 0 || c.Domain.Cmp(built.domain) > 0 {
+			return nil, fmt.Errorf(ugi.ErrInvalidCipher, "domain must be positive and at most A*B")
+		}
+		built.domain = c.Domain
+	}
+
+	return built, nil
+}
+
+// Encrypt permutes a value in [0, Domain).
+func (c *Cipher) Encrypt(m *big.Int) (*big.Int, error) {
+
+	built, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := built.check(m); err != nil {
+		return nil, err
+	}
+
+	v := built.fe(m, nil)
+	for v.Cmp(built.domain) >= 0 {
+		v = built.fe(v, nil)
+	}
+
+	return v, nil
+}
+
+// Decrypt reverses Encrypt.
+func (c *Cipher) Decrypt(m *big.Int) (*big.Int, error) {
+
+	built, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := built.check(m); err != nil {
+		return nil, err
+	}
+
+	v := built.fen(m, nil)
+	for v.Cmp(built.domain) >= 0 {
+		v = built.fen(v, nil)
+	}
+
+	return v, nil
+}
+
+func (c *cipher) check(m *big.Int) error {
+
+	if m.Sign() < 0 || m.Cmp(c.domain) >= 0 {
+		return fmt.Errorf(ugi.ErrCipherDomain, m.String(), c.domain.String())
+	}
+
+	return nil
+}
+
+// f is the round function.
+func (c *cipher) f(j int, arg *big.Int) *big.Int {
+
+	seed := c.keys[j%len(c.keys)]
+	if c.keyLen == 2 {
+		return muk(seed, arg)
+	}
+
+	return big.NewInt(int64(Muk(seed, c.keyLen, arg)))
+}
+
+// fe runs the rounds of the cipher forwards once, without cycle walking. If
+// pass is not nil, every intermediate value is recorded in it.
+func (c *cipher) fe(m *big.Int, pass *Pass) *big.Int {
+
+	ell := big.NewInt(0).Mod(m, c.a)
+	arr := big.NewInt(0).Div(m, c.a)
+	pass.start(m, ell, arr)
+
+	for j := 1; j <= c.rounds; j++ {
+
+		eff := c.f(j-1, arr)
+		tmp := big.NewInt(0).Add(ell, eff)
+		if j%2 != 0 {
+			tmp = tmp.Mod(tmp, c.a)
+		} else {
+			tmp = tmp.Mod(tmp, c.b)
+		}
+
+		pass.round(j, c.keys[(j-1)%len(c.keys)], arr, eff, arr, tmp)
+		ell, arr = arr, tmp
+	}
+
+	var out *big.Int
+	if c.generalized {
+		// After an even number of rounds ell < a and arr < b, and the other
+		// way around after an odd number. Whichever half is below a becomes
+		// the low digit unless the other half is too large to be the high
+		// digit, in which case the result lands in [a*a, a*b).
+		if c.rounds%2 != 0 {
+			ell, arr = arr, ell
+		}
+		if arr.Cmp(c.a) >= 0 {
+			out = big.NewInt(0).Add(big.NewInt(0).Mul(c.a, arr), ell)
+		} else {
+			out = big.NewInt(0).Add(big.NewInt(0).Mul(c.a, ell), arr)
+		}
+	} else if c.rounds%2 != 0 || arr.Cmp(c.a) == 0 {
+		out = big.NewInt(0).Add(big.NewInt(0).Mul(c.a, arr), ell)
+	} else {
+		out = big.NewInt(0).Add(big.NewInt(0).Mul(c.a, ell), arr)
+	}
+
+	pass.finish(out)
+	return out
+}
+
+// fen runs the rounds of the cipher backwards once, without cycle walking. If
+// pass is not nil, every intermediate value is recorded in it.
+func (c *cipher) fen(m *big.Int, pass *Pass) *big.Int {
+
+	ahh := big.NewInt(0).Mod(m, c.a)
+	ale := big.NewInt(0).Div(m, c.a)
+
+	ell := ale
+	arr := ahh
+	if c.generalized {
+		if ale.Cmp(c.a) >= 0 {
+			ell, arr = arr, ell
+		}
+		if c.rounds%2 != 0 {
+			ell, arr = arr, ell
+		}
+	} else {
+		if c.rounds%2 != 0 {
+			ell, arr = arr, ell
+		}
+		if ell.Cmp(c.a) == 0 {
+			ell, arr = arr, ell
+		}
+	}
+	pass.start(m, ell, arr)
+
+	for j := c.rounds; j >= 1; j-- {
+
+		eff := c.f(j-1, ell)
+		useValue := c.a
+		if j%2 == 0 {
+			useValue = c.b
+		}
+
+		tmp := big.NewInt(0).Add(arr, useValue)
+		tmp = tmp.Sub(tmp, big.NewInt(0).Mod(eff, useValue))
+		tmp = tmp.Mod(tmp, useValue)
+
+		pass.round(j, c.keys[(j-1)%len(c.keys)], ell, eff, tmp, ell)
+		ell, arr = tmp, ell
+	}
+
+	out := big.NewInt(0).Add(big.NewInt(0).Mul(c.a, arr), ell)
+	pass.finish(out)
+	return out
+}
@@ -0,0 +1,200 @@
+package ob
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCipherDefaultsMatchFeis(t *testing.T) {
+
+	c := &Cipher{}
+	for _, v := range []int64{0, 1, 0xff, 0xffff, 0x10000, 0xda0300, 0xfffeffff} {
+
+		expected, err := Feis(big.NewInt(v).String())
+		assert.NoError(t, err)
+
+		actual, err := c.Encrypt(big.NewInt(v))
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, actual)
+		}
+
+		back, err := c.Decrypt(actual)
+		if assert.NoError(t, err) {
+			assert.Equal(t, big.NewInt(v), back)
+		}
+	}
+}
+
+func TestCipherIsPermutation(t *testing.T) {
+
+	var testCases = []struct {
+		name   string
+		cipher *Cipher
+		domain int64
+	}{
+		{name: "urbit split", cipher: &Cipher{A: big.NewInt(15), B: big.NewInt(16)}, domain: 240},
+		{name: "uneven split", cipher: &Cipher{A: big.NewInt(10), B: big.NewInt(40)}, domain: 400},
+		{name: "odd rounds", cipher: &Cipher{A: big.NewInt(7), B: big.NewInt(9), Rounds: 5}, domain: 63},
+		{name: "custom keys", cipher: &Cipher{Keys: []uint32{1, 2, 3}, Rounds: 6, A: big.NewInt(20), B: big.NewInt(21)}, domain: 420},
+		{name: "cycle walking", cipher: &Cipher{A: big.NewInt(30), B: big.NewInt(40), Domain: big.NewInt(1000)}, domain: 1000},
+		{name: "domain only", cipher: &Cipher{Domain: big.NewInt(1234)}, domain: 1234},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			seen := map[int64]bool{}
+			for i := int64(0); i < tt.domain; i++ {
+
+				v, err := tt.cipher.Encrypt(big.NewInt(i))
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.True(t, v.Int64() < tt.domain)
+				assert.False(t, seen[v.Int64()], "collision at %d", i)
+				seen[v.Int64()] = true
+
+				back, err := tt.cipher.Decrypt(v)
+				if assert.NoError(t, err) {
+					assert.Equal(t, i, back.Int64())
+				}
+			}
+		})
+	}
+}
+
+func TestCipherErrors(t *testing.T) {
+
+	_, err := (&Cipher{Domain: big.NewInt(100)}).Encrypt(big.NewInt(100))
+	assert.EqualError(t, err, "value 100 is outside of the cipher domain [0, 100)")
+
+	_, err = (&Cipher{}).Decrypt(big.NewInt(-1))
+	assert.EqualError(t, err, "value -1 is outside of the cipher domain [0, 4294901760)")
+
+	_, err = (&Cipher{A: big.NewInt(16), B: big.NewInt(15)}).Encrypt(big.NewInt(1))
+	assert.EqualError(t, err, "invalid cipher: A must not be greater than B")
+
+	_, err = (&Cipher{Domain: big.NewInt(1 << 40), A: big.NewInt(2)}).Encrypt(big.NewInt(1))
+	assert.EqualError(t, err, "invalid cipher: domain must be positive and at most A*B")
+
+	_, err = (&Cipher{Keys: []uint32{}}).Encrypt(big.NewInt(1))
+	assert.EqualError(t, err, "invalid cipher: keys, rounds and bases must be positive")
+}
+
+// hoonFe and hoonFen are Hoon's fe and fen as the package first implemented
+// them, which Fe and Fen must keep matching whatever their parameters.
+func hoonFe(r int, a, b, m *big.Int) *big.Int {
+
+	ell := big.NewInt(0).Mod(m, a)
+	arr := big.NewInt(0).Div(m, a)
+	for j := 1; j <= r; j++ {
+		tmp := big.NewInt(0).Add(ell, F(j-1, arr))
+		if j%2 != 0 {
+			tmp.Mod(tmp, a)
+		} else {
+			tmp.Mod(tmp, b)
+		}
+		ell, arr = arr, tmp
+	}
+
+	if r%2 != 0 || arr.Cmp(a) == 0 {
+		return big.NewInt(0).Add(big.NewInt(0).Mul(a, arr), ell)
+	}
+
+	return big.NewInt(0).Add(big.NewInt(0).Mul(a, ell), arr)
+}
+
+func hoonFen(r int, a, b, m *big.Int) *big.Int {
+
+	ahh := big.NewInt(0).Mod(m, a)
+	ale := big.NewInt(0).Div(m, a)
+	if r%2 != 0 {
+		ahh, ale = ale, ahh
+	}
+
+	ell, arr := ale, ahh
+	if ale.Cmp(a) == 0 {
+		ell, arr = arr, ell
+	}
+	for j := r; j >= 1; j-- {
+		useValue := a
+		if j%2 == 0 {
+			useValue = b
+		}
+		tmp := big.NewInt(0).Add(arr, useValue)
+		tmp.Sub(tmp, big.NewInt(0).Mod(F(j-1, ell), useValue))
+		tmp.Mod(tmp, useValue)
+		ell, arr = tmp, ell
+	}
+
+	return big.NewInt(0).Add(big.NewInt(0).Mul(a, arr), ell)
+}
+
+func TestFeFenMatchHoon(t *testing.T) {
+
+	var testCases = []struct {
+		name string
+		a    int64
+		b    int64
+	}{
+		{name: "255x256", a: 255, b: 256},
+		{name: "7x8", a: 7, b: 8},
+		{name: "10x40", a: 10, b: 40},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			a, b := big.NewInt(tt.a), big.NewInt(tt.b)
+			k := big.NewInt(tt.a * tt.b)
+			for r := 1; r <= 4; r++ {
+				for m := int64(0); m < tt.a*tt.b; m++ {
+
+					v := big.NewInt(m)
+					expected := hoonFe(r, a, b, v)
+					if expected.Cmp(k) >= 0 {
+						expected = hoonFe(r, a, b, expected)
+					}
+					assert.Equal(t, expected, Fe(r, a, b, k, v), "fe r=%d m=%d", r, m)
+
+					expected = hoonFen(r, a, b, v)
+					if expected.Cmp(k) >= 0 {
+						expected = hoonFen(r, a, b, expected)
+					}
+					assert.Equal(t, expected, Fen(r, a, b, k, v), "fen r=%d m=%d", r, m)
+				}
+			}
+		})
+	}
+}
+
+func TestFeFenOddRounds(t *testing.T) {
+
+	// Results of the original implementation with Urbit's split and three
+	// rounds.
+	var testCases = []struct {
+		m   int64
+		fe  int64
+		fen int64
+	}{
+		{m: 0, fe: 1111280168, fen: 3421110479},
+		{m: 1, fe: 3560898135, fen: 2064505812},
+		{m: 65535, fe: 3492299096, fen: 2870680510},
+		{m: 65536, fe: 1753663790, fen: 3300453013},
+		{m: 4294901758, fe: 3282264620, fen: 2293669443},
+		{m: 4294901759, fe: 3148040420, fen: 1727101371},
+		{m: 123456789, fe: 2060467621, fen: 2072193993},
+	}
+
+	for _, tt := range testCases {
+		t.Run(big.NewInt(tt.m).String(), func(t *testing.T) {
+
+			v := big.NewInt(tt.m)
+			assert.Equal(t, big.NewInt(tt.fe), Fe(3, u65535, u65536, uxFFFFFFFF, v))
+			assert.Equal(t, big.NewInt(tt.fen), Fen(3, u65535, u65536, uxFFFFFFFF, v))
+		})
+	}
+
+}
@@ -1,85 +1,97 @@
 package ob
 
 import (
+	"encoding/binary"
 	"math/big"
+	"math/bits"
 )
 
-var (
-	uxFF   = big.NewInt(0xff)
-	uxFF00 = big.NewInt(0xff00)
-	u256   = big.NewInt(256)
+const (
+	murmurC1 uint32 = 0xcc9e2d51
+	murmurC2 uint32 = 0x1b873593
 )
 
 func muk(seed uint32, key *big.Int) *big.Int {
 
-	lo := big.NewInt(0).And(key, uxFF).Int64()
-	hi := big.NewInt(0).Div(big.NewInt(0).And(key, uxFF00), u256).Int64()
-	hashKey := []rune{rune(lo), rune(hi)}
+	var lo big.Word
+	if words := key.Bits(); len(words) > 0 {
+		lo = words[0]
+	}
+	hashKey := [2]byte{byte(lo), byte(lo >> 8)}
 
-	hash := murmurHash(hashKey, seed)
+	hash := Murmur3(hashKey[:], seed)
 	return big.NewInt(int64(hash))
 }
 
-func murmurHash(key []rune, seed uint32) uint32 {
+// Muk is Urbit's muk: the murmur3 hash, with the given seed, of the low
+// length bytes of key taken least significant first. Keys shorter than
+// length are padded with zero bytes. A nil key is taken to be zero, and a
+// negative length to be zero, which hashes no bytes at all.
+func Muk(seed uint32, length int, key *big.Int) uint32 {
+
+	if length < 0 {
+		length = 0
+	}
+
+	var buf []byte
+	if key != nil {
+		buf = key.Bytes()
+	}
+	hashKey := make([]byte, length)
+	for i := 0; i < length && i < len(buf); i++ {
+		hashKey[i] = buf[len(buf)-1-i]
+	}
+
+	return Murmur3(hashKey, seed)
+}
 
-	keyLen := len(key)
-	remainder := keyLen & 3 // len(key) % 4
-	bytes := keyLen - remainder
-	h1 := uint32(seed)
-	c1 := uint32(0xcc9e2d51)
-	c2 := uint32(0x1b873593)
-	var (
-		i   uint32
-		k1  uint32
-		h1b uint32
-	)
+// Murmur3 is the 32-bit x86 variant of MurmurHash3, which is the hash
+// function Urbit uses everywhere.
+func Murmur3(key []byte, seed uint32) uint32 {
 
-	for int(i) < bytes {
+	h1 := seed
+	nblocks := len(key) / 4
 
-		k1 = (uint32(key[i]) & 0xff) |
-			((uint32(key[i+1]) & 0xff) << 8) |
-			((uint32(key[i+2]) & 0xff) << 16) |
-			((uint32(key[i+3]) & 0xff) << 24)
+	for i := 0; i < nblocks; i++ {
 
-		i += 4
+		k1 := binary.LittleEndian.Uint32(key[4*i:])
 
-		k1 = (((k1 & 0xffff) * c1) + ((((k1 >> 16) * c1) & 0xffff) << 16)) & 0xffffffff
-		k1 = (k1 << 15) | (k1 >> 17)
-		k1 = (((k1 & 0xffff) * c2) + ((((k1 >> 16) * c2) & 0xffff) << 16)) & 0xffffffff
+		k1 *= murmurC1
+		k1 = bits.RotateLeft32(k1, 15)
+		k1 *= murmurC2
 
 		h1 ^= k1
-		h1 = (h1 << 13) | (h1 >> 19)
-		h1b = (((h1 & 0xffff) * 5) + ((((h1 >> 16) * 5) & 0xffff) << 16)) & 0xffffffff
-		h1 = (((h1b & 0xffff) + 0x6b64) + ((((h1b >> 16) + 0xe654) & 0xffff) << 16))
+		h1 = bits.RotateLeft32(h1, 13)
+		h1 = h1*5 + 0xe6546b64
 	}
 
-	k1 = 0
+	tail := key[4*nblocks:]
+	var k1 uint32
 
-	switch remainder {
+	switch len(tail) {
 
 	case 3:
-		k1 ^= uint32(key[i+2]&0xff) << 16
+		k1 ^= uint32(tail[2]) << 16
 		fallthrough
 
 	case 2:
-		k1 ^= uint32(key[i+1]&0xff) << 8
+		k1 ^= uint32(tail[1]) << 8
 		fallthrough
 
 	case 1:
-		k1 ^= uint32(key[i] & 0xff)
+		k1 ^= uint32(tail[0])
+		k1 *= murmurC1
+		k1 = bits.RotateLeft32(k1, 15)
+		k1 *= murmurC2
+		h1 ^= k1
 	}
 
-	k1 = (((k1 & 0xffff) * c1) + ((((k1 >> 16) * c1) & 0xffff) << 16)) & 0xffffffff
-	k1 = (k1 << 15) | (k1 >> 17)
-	k1 = (((k1 & 0xffff) * c2) + ((((k1 >> 16) * c2) & 0xffff) << 16)) & 0xffffffff
-	h1 ^= k1
-
-	h1 ^= uint32(keyLen)
+	h1 ^= uint32(len(key))
 
 	h1 ^= h1 >> 16
-	h1 = (((h1 & 0xffff) * 0x85ebca6b) + ((((h1 >> 16) * 0x85ebca6b) & 0xffff) << 16)) & 0xffffffff
+	h1 *= 0x85ebca6b
 	h1 ^= h1 >> 13
-	h1 = (((h1 & 0xffff) * 0xc2b2ae35) + ((((h1 >> 16) * 0xc2b2ae35) & 0xffff) << 16)) & 0xffffffff
+	h1 *= 0xc2b2ae35
 	h1 ^= h1 >> 16
 
 	return h1
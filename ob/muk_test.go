@@ -0,0 +1,55 @@
+package ob
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMurmur3(t *testing.T) {
+
+	var testCases = []struct {
+		key  string
+		seed uint32
+		out  uint32
+	}{
+		{key: "", seed: 0, out: 0},
+		{key: "", seed: 1, out: 0x514e28b7},
+		{key: "", seed: 0xffffffff, out: 0x81f16f39},
+		{key: "\x00\x00\x00\x00", seed: 0, out: 0x2362f9de},
+		{key: "\xff\xff\xff\xff", seed: 0, out: 0x76293b50},
+		{key: "\x21\x43\x65\x87", seed: 0, out: 0xf55b516b},
+		{key: "\x21\x43\x65\x87", seed: 0x5082edee, out: 0x2362f9de},
+		{key: "\x21\x43\x65", seed: 0, out: 0x7e4a8634},
+		{key: "\x21\x43", seed: 0, out: 0xa0f7b07a},
+		{key: "\x21", seed: 0, out: 0x72661cf4},
+		{key: "a", seed: 0x9747b28c, out: 0x7fa09ea6},
+		{key: "abc", seed: 0x9747b28c, out: 0xc84a62dd},
+		{key: "aaaa", seed: 0x9747b28c, out: 0x5a97808a},
+		{key: "Hello, world!", seed: 0x9747b28c, out: 0x24884cba},
+		{key: "The quick brown fox jumps over the lazy dog", seed: 0x9747b28c, out: 0x2fa826cd},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.key, func(t *testing.T) {
+
+			assert.Equal(t, tt.out, Murmur3([]byte(tt.key), tt.seed))
+		})
+	}
+}
+
+func TestMuk(t *testing.T) {
+
+	// The key is read least significant byte first and padded or truncated
+	// to the requested length.
+	key := big.NewInt(0x87654321)
+	assert.Equal(t, uint32(0xf55b516b), Muk(0, 4, key))
+	assert.Equal(t, uint32(0xa0f7b07a), Muk(0, 2, key))
+	assert.Equal(t, uint32(0x2362f9de), Muk(0, 4, big.NewInt(0)))
+	assert.Equal(t, uint32(0x2362f9de), Muk(0, 4, nil))
+	assert.Equal(t, Murmur3(nil, 7), Muk(7, -1, key))
+
+	// muk is the two-byte form used by the Feistel rounds.
+	assert.Equal(t, big.NewInt(int64(Muk(raku[0], 2, big.NewInt(0x1234)))), muk(raku[0], big.NewInt(0x1234)))
+}
@@ -102,11 +102,9 @@ func Feis(arg string) (*big.Int, error) {
 		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
 	}
 
-	return Fe(4, u65535, u65536, uxFFFFFFFF, v), nil
+	return Fe(defaultRounds, u65535, u65536, uxFFFFFFFF, v), nil
 }
 
-// TODO: merge Fe and Fen code to accept an additional function argument.
-
 func Fe(
 	r int,
 	a,
@@ -115,55 +113,14 @@ func Fe(
 	m *big.Int,
 ) *big.Int {
 
-	c := fe(r, a, b, m)
+	ciph := newCipher(raku, r, a, b)
+	c := ciph.fe(m, nil)
 
 	if c.Cmp(k) == -1 {
 		return c
 	}
 
-	return fe(r, a, b, c)
-}
-
-func fe(
-	r int,
-	a,
-	b,
-	m *big.Int,
-) *big.Int {
-
-	left := big.NewInt(0).Mod(m, a)
-	right := big.NewInt(0).Div(m, a)
-
-	return feLoop(r, a, b, 1, left, right)
-}
-
-func feLoop(
-	r int,
-	a,
-	b *big.Int,
-	j int,
-	ell,
-	arr *big.Int,
-) *big.Int {
-
-	if j > r {
-
-		if r%2 != 0 || arr.Cmp(a) == 0 {
-			return big.NewInt(0).Add(big.NewInt(0).Mul(a, arr), ell)
-		}
-
-		return big.NewInt(0).Add(big.NewInt(0).Mul(a, ell), arr)
-	}
-
-	eff := F(j-1, arr)
-	tmp := big.NewInt(0).Add(ell, eff)
-	if j%2 != 0 {
-		tmp = tmp.Mod(tmp, a)
-	} else {
-		tmp = tmp.Mod(tmp, b)
-	}
-
-	return feLoop(r, a, b, j+1, arr, tmp)
+	return ciph.fe(c, nil)
 }
 
 func Tail(arg string) (*big.Int, error) {
@@ -173,7 +130,7 @@ func Tail(arg string) (*big.Int, error) {
 		return nil, fmt.Errorf(ugi.ErrInvalidInt, arg)
 	}
 
-	return Fen(4, u65535, u65536, uxFFFFFFFF, v), nil
+	return Fen(defaultRounds, u65535, u65536, uxFFFFFFFF, v), nil
 }
 
 func Fen(
@@ -184,60 +141,12 @@ func Fen(
 	m *big.Int,
 ) *big.Int {
 
-	c := fen(r, a, b, m)
+	ciph := newCipher(raku, r, a, b)
+	c := ciph.fen(m, nil)
 
 	if c.Cmp(k) == -1 {
 		return c
 	}
 
-	return fen(r, a, b, c)
-}
-
-func fen(
-	r int,
-	a,
-	b,
-	m *big.Int,
-) *big.Int {
-
-	ahh := big.NewInt(0).Mod(m, a)
-	ale := big.NewInt(0).Div(m, a)
-	if r%2 != 0 {
-		ahh, ale = ale, ahh
-	}
-
-	left := ale
-	right := ahh
-	if ale.Cmp(a) == 0 {
-		left, right = right, left
-	}
-
-	return fenLoop(a, b, r, left, right)
-}
-
-func fenLoop(
-	a,
-	b *big.Int,
-	j int,
-	ell,
-	arr *big.Int,
-) *big.Int {
-
-	if j < 1 {
-		return big.NewInt(0).Add(big.NewInt(0).Mul(a, arr), ell)
-	}
-
-	eff := F(j-1, ell)
-	tmp := big.NewInt(0)
-	useValue := a
-
-	if j%2 == 0 {
-		useValue = b
-	}
-
-	tmp = tmp.Add(arr, useValue)
-	tmp = tmp.Sub(tmp, big.NewInt(0).Mod(eff, useValue))
-	tmp = tmp.Mod(tmp, useValue)
-
-	return fenLoop(a, b, j-1, tmp, ell)
+	return ciph.fen(c, nil)
 }
@@ -0,0 +1,121 @@
+package ob
+
+import (
+	"math/big"
+)
+
+// Round records one round of the Feistel cipher.
+type Round struct {
+	// Round counts from 1.
+	Round int
+	// Key is the seed of the round function.
+	Key uint32
+	// Arg is the half that was hashed, and Muk is its hash.
+	Arg *big.Int
+	Muk *big.Int
+	// Ell and Arr are the halves after the round.
+	Ell *big.Int
+	Arr *big.Int
+}
+
+// Pass records one run through every round of the cipher.
+type Pass struct {
+	In *big.Int
+	// Ell and Arr are the halves In was split into.
+	Ell    *big.Int
+	Arr    *big.Int
+	Rounds []Round
+	Out    *big.Int
+}
+
+func (p *Pass) start(in, ell, arr *big.Int) {
+
+	if p == nil {
+		return
+	}
+
+	p.In, p.Ell, p.Arr = in, ell, arr
+}
+
+func (p *Pass) round(j int, key uint32, arg, muk, ell, arr *big.Int) {
+
+	if p == nil {
+		return
+	}
+
+	p.Rounds = append(p.Rounds, Round{Round: j, Key: key, Arg: arg, Muk: muk, Ell: ell, Arr: arr})
+}
+
+func (p *Pass) finish(out *big.Int) {
+
+	if p == nil {
+		return
+	}
+
+	p.Out = out
+}
+
+/*
+Trace records every step Fein or Fynd took to transform a value.
+
+Only the low 32 bits of a value of up to 64 bits are scrambled, and only when
+they are at least 0x10000; Hi and Lo hold the split, and are nil for values
+outside of that range. Lo - 0x10000 is run through Feis or Tail, whose passes
+are recorded in Passes. A second pass is the cycle walking retry in Fe and
+Fen, which Urbit's parameters never actually need.
+*/
+type Trace struct {
+	In *big.Int
+	// Inverse reports whether this is a trace of Fynd rather than Fein.
+	Inverse   bool
+	Hi        *big.Int
+	Lo        *big.Int
+	Scrambled bool
+	Passes    []Pass
+	Out       *big.Int
+}
+
+// TraceFein computes Fein, recording every intermediate value.
+func TraceFein(pyn *big.Int) *Trace {
+
+	return trace(pyn, false)
+}
+
+// TraceFynd computes Fynd, recording every intermediate value.
+func TraceFynd(cry *big.Int) *Trace {
+
+	return trace(cry, true)
+}
+
+func trace(v *big.Int, inverse bool) *Trace {
+
+	t := &Trace{In: v, Inverse: inverse, Out: v}
+	if v.Sign() < 0 || v.Cmp(uxFFFFFFFFFFFFFFFF) > 0 {
+		return t
+	}
+
+	t.Hi, t.Lo = loopHiLoInit(v)
+	if t.Lo.Cmp(ux10000) < 0 {
+		return t
+	}
+	t.Scrambled = true
+
+	ciph := newCipher(raku, defaultRounds, u65535, u65536)
+	step := ciph.fe
+	if inverse {
+		step = ciph.fen
+	}
+
+	m := big.NewInt(0).Sub(t.Lo, ux10000)
+	for len(t.Passes) < 2 {
+		var pass Pass
+		m = step(m, &pass)
+		t.Passes = append(t.Passes, pass)
+		if m.Cmp(uxFFFFFFFF) < 0 {
+			break
+		}
+	}
+
+	t.Out = big.NewInt(0).Or(t.Hi, big.NewInt(0).Add(ux10000, m))
+	return t
+}
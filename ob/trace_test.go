@@ -0,0 +1,46 @@
+package ob
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceMatchesFein(t *testing.T) {
+
+	for _, v := range []string{"0", "65535", "65536", "1624961343", "4294967295", "4294967296", "4773359030139285823", "18446744073709551616"} {
+		t.Run(v, func(t *testing.T) {
+
+			in, _ := big.NewInt(0).SetString(v, 10)
+
+			fein, err := Fein(v)
+			assert.NoError(t, err)
+
+			trace := TraceFein(in)
+			assert.Equal(t, fein, trace.Out)
+			assert.Equal(t, trace.Scrambled, len(trace.Passes) == 1)
+
+			back := TraceFynd(fein)
+			assert.Equal(t, in, back.Out)
+			assert.Equal(t, trace.Scrambled, back.Scrambled)
+		})
+	}
+}
+
+func TestTraceRounds(t *testing.T) {
+
+	trace := TraceFein(big.NewInt(1624961343))
+	if !assert.Len(t, trace.Passes, 1) {
+		return
+	}
+
+	pass := trace.Passes[0]
+	assert.Len(t, pass.Rounds, 4)
+	for i, r := range pass.Rounds {
+		assert.Equal(t, i+1, r.Round)
+		assert.Equal(t, raku[i], r.Key)
+		assert.Equal(t, F(i, r.Arg), r.Muk)
+	}
+	assert.Equal(t, "74350415", pass.Out.String())
+}
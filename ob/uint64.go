@@ -0,0 +1,104 @@
+package ob
+
+// Fein64 is Fein for values that fit in a uint64, computed without any
+// big.Int arithmetic or allocation.
+func Fein64(pyn uint64) uint64 {
+
+	lo := pyn & 0xffffffff
+	if lo < 0x10000 {
+		return pyn
+	}
+
+	return pyn&^0xffffffff | (0x10000 + uint64(fe64(uint32(lo-0x10000))))
+}
+
+// Fynd64 is Fynd for values that fit in a uint64, computed without any
+// big.Int arithmetic or allocation.
+func Fynd64(cry uint64) uint64 {
+
+	lo := cry & 0xffffffff
+	if lo < 0x10000 {
+		return cry
+	}
+
+	return cry&^0xffffffff | (0x10000 + uint64(fen64(uint32(lo-0x10000))))
+}
+
+// f64 is F on the low 16 bits of arg.
+func f64(j int, arg uint32) uint32 {
+
+	key := [2]byte{byte(arg), byte(arg >> 8)}
+	return Murmur3(key[:], raku[j])
+}
+
+// fe64 is Fe with Urbit's parameters: four rounds over a 65535 by 65536
+// split, with a bound of 0xffffffff.
+func fe64(m uint32) uint32 {
+
+	c := feRounds64(m)
+	if c < 0xffffffff {
+		return c
+	}
+
+	return feRounds64(c)
+}
+
+func feRounds64(m uint32) uint32 {
+
+	ell := uint64(m % 65535)
+	arr := uint64(m / 65535)
+
+	for j := 1; j <= defaultRounds; j++ {
+
+		tmp := ell + uint64(f64(j-1, uint32(arr)))
+		if j%2 != 0 {
+			tmp %= 65535
+		} else {
+			tmp %= 65536
+		}
+
+		ell, arr = arr, tmp
+	}
+
+	if arr >= 65535 {
+		return uint32(65535*arr + ell)
+	}
+
+	return uint32(65535*ell + arr)
+}
+
+// fen64 is Fen with Urbit's parameters.
+func fen64(m uint32) uint32 {
+
+	c := fenRounds64(m)
+	if c < 0xffffffff {
+		return c
+	}
+
+	return fenRounds64(c)
+}
+
+func fenRounds64(m uint32) uint32 {
+
+	ahh := uint64(m % 65535)
+	ale := uint64(m / 65535)
+
+	ell, arr := ale, ahh
+	if ale >= 65535 {
+		ell, arr = arr, ell
+	}
+
+	for j := defaultRounds; j >= 1; j-- {
+
+		eff := uint64(f64(j-1, uint32(ell)))
+		useValue := uint64(65535)
+		if j%2 == 0 {
+			useValue = 65536
+		}
+
+		tmp := (arr + useValue - eff%useValue) % useValue
+		ell, arr = tmp, ell
+	}
+
+	return uint32(65535*arr + ell)
+}
@@ -0,0 +1,50 @@
+package ob
+
+import (
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFein64MatchesFein(t *testing.T) {
+
+	values := []uint64{0, 1, 0xffff, 0x10000, 0x10001, 0x60daf13f, 0xfffeffff, 0xffff0000, 0xffffffff,
+		0x100000000, 0x10000ffff, 0x423e60bf60daf13f, 0xffffffffffffffff}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		values = append(values, uint64(r.Uint32()), r.Uint64())
+	}
+
+	for _, v := range values {
+
+		fein, err := Fein(strconv.FormatUint(v, 10))
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.Equal(t, fein.Uint64(), Fein64(v), "fein %d", v) {
+			return
+		}
+
+		fynd, err := Fynd(big.NewInt(0).SetUint64(v))
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.Equal(t, fynd.Uint64(), Fynd64(v), "fynd %d", v) {
+			return
+		}
+
+		assert.Equal(t, v, Fynd64(Fein64(v)))
+	}
+}
+
+func TestFein64Allocations(t *testing.T) {
+
+	allocs := testing.AllocsPerRun(100, func() {
+		Fynd64(Fein64(0x423e60bf60daf13f))
+	})
+	assert.Zero(t, allocs)
+}
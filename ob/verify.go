@@ -0,0 +1,255 @@
+package ob
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strconv"
+	"sync"
+
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+const (
+	// DefaultVerifyChunk is the number of values each worker checks at a time
+	// if VerifyOptions doesn't say otherwise.
+	DefaultVerifyChunk uint64 = 1 << 20
+
+	// DefaultVerifySample is how often Verify checks a value against the
+	// big.Int Fein and Fynd if VerifyOptions doesn't say otherwise: every
+	// DefaultVerifySample-th value of each chunk.
+	DefaultVerifySample uint64 = 1 << 10
+
+	// maxVerifyExamples caps how many failing values VerifyState keeps.
+	maxVerifyExamples int = 100
+)
+
+// verifyFuncs are the functions Verify checks, which tests replace to break
+// them.
+type verifyFuncs struct {
+	fein    func(uint64) uint64
+	fynd    func(uint64) uint64
+	feinBig func(string) (*big.Int, error)
+	fyndBig func(*big.Int) (*big.Int, error)
+}
+
+// VerifyState is the progress of Verify over the range [Start, End], which
+// includes End so that every uint64 can be checked. It can be saved, for
+// example as JSON, and passed to Verify again to resume.
+type VerifyState struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	// Next is the first value not known to be checked: every value in
+	// [Start, Next) has been.
+	Next uint64 `json:"next"`
+	// Complete is set once End has been checked too, which Next can't show
+	// when End is the largest uint64.
+	Complete bool   `json:"complete"`
+	Failures uint64 `json:"failures"`
+	// Examples are the first of the values that failed, in order.
+	Examples []uint64 `json:"examples,omitempty"`
+}
+
+// NewVerifyState returns the state of a verification of [start, end] that
+// hasn't started yet.
+func NewVerifyState(start, end uint64) VerifyState {
+
+	return VerifyState{Start: start, End: end, Next: start}
+}
+
+// Done reports whether the whole range has been checked.
+func (s VerifyState) Done() bool {
+
+	return s.Complete
+}
+
+// VerifyOptions configures Verify. The zero value is ready to use.
+type VerifyOptions struct {
+	// Workers is the number of goroutines checking values. Zero means
+	// runtime.GOMAXPROCS(0).
+	Workers int
+	// Chunk is the number of values a worker checks at a time. Zero means
+	// DefaultVerifyChunk.
+	Chunk uint64
+	// Sample is how often a value is checked against the big.Int Fein and
+	// Fynd too: the first value of each chunk and every Sample-th after it.
+	// Zero means DefaultVerifySample, and 1 checks every value, which is
+	// far slower.
+	Sample uint64
+	// Progress, if not nil, is called with the state after every chunk that
+	// moves Next forward. It is never called concurrently, and the state it
+	// is given is safe to save as a checkpoint.
+	Progress func(VerifyState)
+}
+
+// verifyChunk is the range [start, end] that a worker checks.
+type verifyChunk struct {
+	start, end uint64
+	failures   uint64
+	examples   []uint64
+}
+
+/*
+Verify checks that Fein64 and Fynd64 are inverse permutations over the range
+of the given state, from state.Next up to and including state.End, in
+parallel. Only the uint64 fast path is checked at every value: the big.Int
+Fein and Fynd, which Fein64 and Fynd64 must agree with, are compared on just a
+sample of each chunk, by default its first value and every 1024th after it,
+so 1 in 1024 values. opts.Sample changes the rate.
+
+Every value x must satisfy Fynd(Fein(x)) == x and Fein(Fynd(x)) == x, and
+Fein(x) must keep the upper 32 bits of x and whether its lower 32 bits are
+scrambled at all. The first condition means that no two values can collide:
+Fein(x) == Fein(y) would give x == Fynd(Fein(x)) == Fynd(Fein(y)) == y. So
+over a whole block of 2^32 values, Fein is a permutation of the block.
+
+If ctx is canceled, Verify returns the state so far, which can be passed to
+Verify to resume, along with ctx.Err(). It fails if Start is after End.
+*/
+func Verify(ctx context.Context, state VerifyState, opts VerifyOptions) (VerifyState, error) {
+
+	return verify(ctx, state, opts, verifyFuncs{fein: Fein64, fynd: Fynd64, feinBig: Fein, fyndBig: Fynd})
+}
+
+// verify is Verify with the functions it checks.
+func verify(ctx context.Context, state VerifyState, opts VerifyOptions, funcs verifyFuncs) (VerifyState, error) {
+
+	if state.Start > state.End {
+		return state, fmt.Errorf(ugi.ErrVerifyRange, state.Start, state.End)
+	}
+	if state.Complete {
+		return state, nil
+	}
+	if state.Next < state.Start || state.Next > state.End {
+		state.Next = state.Start
+	}
+
+	chunk := opts.Chunk
+	if chunk == 0 {
+		chunk = DefaultVerifyChunk
+	}
+	sample := opts.Sample
+	if sample == 0 {
+		sample = DefaultVerifySample
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan verifyChunk)
+	done := make(chan verifyChunk)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				funcs.verifyRange(&c, sample)
+				done <- c
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for start := state.Next; ; {
+			end := state.End
+			if end-start >= chunk {
+				end = start + chunk - 1
+			}
+			select {
+			case jobs <- verifyChunk{start: start, end: end}:
+			case <-ctx.Done():
+				return
+			}
+			if end == state.End {
+				return
+			}
+			start = end + 1
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// Chunks finish out of order, so hold on to them until every chunk
+	// before them has finished too, which keeps Next a safe checkpoint.
+	finished := map[uint64]verifyChunk{}
+	for c := range done {
+		finished[c.start] = c
+		advanced := false
+		for !state.Complete {
+			next, ok := finished[state.Next]
+			if !ok {
+				break
+			}
+			delete(finished, state.Next)
+			state.Failures += next.failures
+			for _, x := range next.examples {
+				if len(state.Examples) < maxVerifyExamples {
+					state.Examples = append(state.Examples, x)
+				}
+			}
+			// Next wraps to 0 after the largest uint64, where Complete
+			// tells the two apart.
+			state.Next = next.end + 1
+			state.Complete = next.end == state.End
+			advanced = true
+		}
+		if advanced && opts.Progress != nil {
+			opts.Progress(state)
+		}
+	}
+
+	if !state.Done() {
+		return state, ctx.Err()
+	}
+
+	return state, nil
+}
+
+// verifyRange checks every value of c, and every sample-th value of it against
+// the big.Int functions as well.
+func (f verifyFuncs) verifyRange(c *verifyChunk, sample uint64) {
+
+	for x := c.start; ; x++ {
+
+		y := f.fein(x)
+		ok := f.fynd(y) == x &&
+			f.fein(f.fynd(x)) == x &&
+			y>>32 == x>>32 &&
+			(y&0xffffffff < 0x10000) == (x&0xffffffff < 0x10000)
+		if ok && (x-c.start)%sample == 0 {
+			ok = f.agreesWithBig(x, y)
+		}
+
+		if !ok {
+			c.failures++
+			if len(c.examples) < maxVerifyExamples {
+				c.examples = append(c.examples, x)
+			}
+		}
+
+		if x == c.end {
+			return
+		}
+	}
+}
+
+// agreesWithBig reports whether Fein(x) == y and Fynd(y) == x for the big.Int
+// Fein and Fynd.
+func (f verifyFuncs) agreesWithBig(x, y uint64) bool {
+
+	fein, err := f.feinBig(strconv.FormatUint(x, 10))
+	if err != nil || !fein.IsUint64() || fein.Uint64() != y {
+		return false
+	}
+
+	fynd, err := f.fyndBig(big.NewInt(0).SetUint64(y))
+	return err == nil && fynd.IsUint64() && fynd.Uint64() == x
+}
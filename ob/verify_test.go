@@ -0,0 +1,120 @@
+package ob
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+
+	var testCases = []struct {
+		name  string
+		start uint64
+		end   uint64
+	}{
+		{name: "galaxies and stars", start: 0, end: 0xffff},
+		{name: "first planets", start: 0xff00, end: 0x2ffff},
+		{name: "last planets", start: 0xffff0000, end: 0x10000ffff},
+		{name: "top moons", start: 0xffffffffffff0000, end: 0xffffffffffffffff},
+		{name: "one value", start: 0x10000, end: 0x10000},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var progress []uint64
+			state, err := Verify(context.Background(), NewVerifyState(tt.start, tt.end), VerifyOptions{
+				Workers: 4,
+				Chunk:   4096,
+				Progress: func(s VerifyState) {
+					if !s.Done() {
+						progress = append(progress, s.Next)
+					}
+				},
+			})
+
+			assert.NoError(t, err)
+			assert.True(t, state.Done())
+			assert.Equal(t, tt.end+1, state.Next)
+			assert.Zero(t, state.Failures)
+			assert.Empty(t, state.Examples)
+			assert.IsIncreasing(t, progress)
+		})
+	}
+}
+
+func TestVerifyResume(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var checkpoint VerifyState
+	state, err := Verify(ctx, NewVerifyState(0x10000, 0x8ffff), VerifyOptions{
+		Workers: 2,
+		Chunk:   1024,
+		Progress: func(s VerifyState) {
+			checkpoint = s
+			if s.Next >= 0x20000 {
+				cancel()
+			}
+		},
+	})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, state.Done())
+	assert.Equal(t, checkpoint, state)
+
+	state, err = Verify(context.Background(), state, VerifyOptions{Chunk: 1024})
+	assert.NoError(t, err)
+	assert.True(t, state.Done())
+	assert.Equal(t, uint64(0x90000), state.Next)
+}
+
+func TestVerifyRange(t *testing.T) {
+
+	_, err := Verify(context.Background(), NewVerifyState(10, 5), VerifyOptions{})
+	assert.EqualError(t, err, "invalid range [10, 5]: start is after end")
+}
+
+func TestVerifyFailures(t *testing.T) {
+
+	funcs := testVerifyFuncs()
+	funcs.fein = func(x uint64) uint64 {
+		if x%1000 == 0 {
+			return Fein64(x + 1)
+		}
+		return Fein64(x)
+	}
+
+	state, err := verify(context.Background(), NewVerifyState(0x10000, 0x10000+199999), VerifyOptions{Chunk: 1000}, funcs)
+	assert.NoError(t, err)
+	// Every broken value fails, and so does every value that Fynd sends to one.
+	assert.GreaterOrEqual(t, state.Failures, uint64(200))
+	if assert.Len(t, state.Examples, 100) {
+		assert.Contains(t, state.Examples, uint64(66000))
+		assert.IsIncreasing(t, state.Examples)
+	}
+}
+
+func TestVerifyAgainstBig(t *testing.T) {
+
+	// Inverses that disagree with Fein and Fynd only fail the sampled check.
+	funcs := testVerifyFuncs()
+	identity := func(x uint64) uint64 { return x }
+	funcs.fein, funcs.fynd = identity, identity
+
+	state, err := verify(context.Background(), NewVerifyState(0x10000, 0x10000+8191), VerifyOptions{Chunk: 4096}, funcs)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), state.Failures)
+	assert.Equal(t, []uint64{0x10000, 0x10400, 0x10800, 0x10c00, 0x11000, 0x11400, 0x11800, 0x11c00}, state.Examples)
+
+	state, err = verify(context.Background(), NewVerifyState(0x10000, 0x10000+8191), VerifyOptions{Chunk: 4096, Sample: 2048}, funcs)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{0x10000, 0x10800, 0x11000, 0x11800}, state.Examples)
+}
+
+func testVerifyFuncs() verifyFuncs {
+
+	return verifyFuncs{fein: Fein64, fynd: Fynd64, feinBig: Fein, fyndBig: Fynd}
+}
@@ -0,0 +1,172 @@
+/*
+Package urbauth identifies which ship an HTTP request is for from the
+urbauth-~ship cookies that Urbit's Eyre sets when a client logs in.
+
+Middleware puts the ship into the request context, where handlers find it
+with FromContext:
+
+	handler = urbauth.Middleware(urbauth.Options{
+		Policies: []urbauth.Policy{urbauth.AllowClasses(co.ShipClassPlanet, co.ShipClassStar)},
+	})(handler)
+*/
+package urbauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deelawn/urbit-gob/co"
+	ugi "github.com/deelawn/urbit-gob/internal"
+)
+
+// CookiePrefix starts the name of every urbauth cookie; the rest of the name
+// is the @p of the ship.
+const CookiePrefix string = "urbauth-"
+
+// CookieName returns the name of the urbauth cookie for a ship.
+func CookieName(ship co.Ship) string {
+
+	return CookiePrefix + ship.String()
+}
+
+// ParseCookieName returns the ship an urbauth cookie is for. The @p must be
+// canonical.
+func ParseCookieName(name string) (co.Ship, error) {
+
+	if !strings.HasPrefix(name, CookiePrefix) {
+		return co.Ship{}, fmt.Errorf(ugi.ErrUrbauthCookie, name)
+	}
+
+	ship, err := co.ParseShip(name[len(CookiePrefix):])
+	if err != nil {
+		return co.Ship{}, fmt.Errorf(ugi.ErrUrbauthCookie, name)
+	}
+
+	return ship, nil
+}
+
+// ShipFromRequest returns the ship the urbauth cookies of a request are for,
+// and whether there were any. It is an error for a cookie name to be invalid,
+// or for there to be cookies for more than one ship.
+func ShipFromRequest(r *http.Request) (co.Ship, bool, error) {
+
+	var (
+		ship  co.Ship
+		found bool
+	)
+
+	for _, cookie := range r.Cookies() {
+
+		if !strings.HasPrefix(cookie.Name, CookiePrefix) {
+			continue
+		}
+
+		s, err := ParseCookieName(cookie.Name)
+		if err != nil {
+			return co.Ship{}, false, err
+		}
+
+		if found && s != ship {
+			return co.Ship{}, false, fmt.Errorf(ugi.ErrUrbauthAmbiguous, ship, s)
+		}
+		ship, found = s, true
+	}
+
+	return ship, found, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries a ship.
+func NewContext(ctx context.Context, ship co.Ship) context.Context {
+
+	return context.WithValue(ctx, contextKey{}, ship)
+}
+
+// FromContext returns the ship carried by ctx, if any.
+func FromContext(ctx context.Context) (co.Ship, bool) {
+
+	ship, ok := ctx.Value(contextKey{}).(co.Ship)
+	return ship, ok
+}
+
+// Policy decides whether a request for a ship may go ahead. Returning an
+// error rejects it.
+type Policy func(r *http.Request, ship co.Ship) error
+
+// AllowClasses returns a Policy that rejects ships of any other class.
+func AllowClasses(classes ...string) Policy {
+
+	return func(r *http.Request, ship co.Ship) error {
+
+		clan := ship.Clan()
+		for _, class := range classes {
+			if class == clan {
+				return nil
+			}
+		}
+
+		return fmt.Errorf(ugi.ErrShipClass, ship, clan, strings.Join(classes, " or "))
+	}
+}
+
+// Options configures Middleware.
+type Options struct {
+	// Policies are checked in order for every request with a ship.
+	Policies []Policy
+	// Optional lets requests without urbauth cookies through without a ship
+	// in their context, instead of rejecting them.
+	Optional bool
+	// ErrorHandler writes the response for a rejected request. By default the
+	// error is written as plain text with the status code.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+/*
+Middleware returns an http.Handler wrapper that finds the ship of every
+request with ShipFromRequest and adds it to the request context.
+
+Requests are rejected with 401 Unauthorized if they have no urbauth cookie,
+unless Optional is set; with 400 Bad Request if their cookies are invalid; and
+with 403 Forbidden if a policy rejects their ship.
+*/
+func Middleware(opts Options) func(http.Handler) http.Handler {
+
+	reject := opts.ErrorHandler
+	if reject == nil {
+		reject = func(w http.ResponseWriter, r *http.Request, status int, err error) {
+			http.Error(w, err.Error(), status)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			ship, found, err := ShipFromRequest(r)
+			if err != nil {
+				reject(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			if !found {
+				if !opts.Optional {
+					reject(w, r, http.StatusUnauthorized, fmt.Errorf(ugi.ErrUrbauthMissing))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, policy := range opts.Policies {
+				if err := policy(r, ship); err != nil {
+					reject(w, r, http.StatusForbidden, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), ship)))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package urbauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deelawn/urbit-gob/co"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCookieName(t *testing.T) {
+
+	var testCases = []struct {
+		in  string
+		out string
+		err string
+	}{
+		{in: "urbauth-~zod", out: "~zod"},
+		{in: "urbauth-~sampel-palnet", out: "~sampel-palnet"},
+		{in: "urbauth-~doznec", err: "invalid urbauth cookie name: urbauth-~doznec"},
+		{in: "urbauth-sampel-palnet", err: "invalid urbauth cookie name: urbauth-sampel-palnet"},
+		{in: "session", err: "invalid urbauth cookie name: session"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+
+			ship, err := ParseCookieName(tt.in)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.out, ship.String())
+				assert.Equal(t, tt.in, CookieName(ship))
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		ship, ok := FromContext(r.Context())
+		if !ok {
+			_, _ = w.Write([]byte("anonymous"))
+			return
+		}
+		_, _ = w.Write([]byte(ship.String()))
+	}
+
+	planets := AllowClasses(co.ShipClassPlanet)
+
+	var testCases = []struct {
+		name    string
+		opts    Options
+		cookies []string
+		status  int
+		body    string
+	}{
+		{
+			name:    "ship",
+			cookies: []string{"other=1", "urbauth-~sampel-palnet=0v1"},
+			status:  http.StatusOK,
+			body:    "~sampel-palnet",
+		},
+		{
+			name:    "same ship twice",
+			cookies: []string{"urbauth-~sampel-palnet=0v1", "urbauth-~sampel-palnet=0v2"},
+			status:  http.StatusOK,
+			body:    "~sampel-palnet",
+		},
+		{
+			name:   "missing",
+			status: http.StatusUnauthorized,
+			body:   "no urbauth cookie\n",
+		},
+		{
+			name:   "optional",
+			opts:   Options{Optional: true},
+			status: http.StatusOK,
+			body:   "anonymous",
+		},
+		{
+			name:    "invalid",
+			cookies: []string{"urbauth-~sampel-palnez=0v1"},
+			status:  http.StatusBadRequest,
+			body:    "invalid urbauth cookie name: urbauth-~sampel-palnez\n",
+		},
+		{
+			name:    "ambiguous",
+			cookies: []string{"urbauth-~sampel-palnet=0v1", "urbauth-~zod=0v2"},
+			status:  http.StatusBadRequest,
+			body:    "urbauth cookies for more than one ship: ~sampel-palnet and ~zod\n",
+		},
+		{
+			name:    "allowed class",
+			opts:    Options{Policies: []Policy{planets}},
+			cookies: []string{"urbauth-~sampel-palnet=0v1"},
+			status:  http.StatusOK,
+			body:    "~sampel-palnet",
+		},
+		{
+			name:    "rejected class",
+			opts:    Options{Policies: []Policy{planets}},
+			cookies: []string{"urbauth-~marzod=0v1"},
+			status:  http.StatusForbidden,
+			body:    "~marzod is a star, but it must be a planet\n",
+		},
+		{
+			name: "error handler",
+			opts: Options{ErrorHandler: func(w http.ResponseWriter, r *http.Request, status int, err error) {
+				w.WriteHeader(http.StatusTeapot)
+			}},
+			status: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, cookie := range tt.cookies {
+				r.Header.Add("Cookie", cookie)
+			}
+			w := httptest.NewRecorder()
+
+			Middleware(tt.opts)(http.HandlerFunc(handler)).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.body, w.Body.String())
+		})
+	}
+}